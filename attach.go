@@ -15,7 +15,7 @@ import (
 	"golang.org/x/crypto/ssh/terminal"
 )
 
-func attach(sessionInfo *SessionInfo) error {
+func attach(sessionInfo *SessionInfo, readOnly bool) error {
 	fmt.Printf("Waiting for server to be online... (%s)\n", sessionInfo.fdPath)
 
 	err := waitForFdSock(sessionInfo)
@@ -33,11 +33,18 @@ func attach(sessionInfo *SessionInfo) error {
 	defer fmt.Print("\x1b[?1049l")
 	fmt.Print("\x1b[?1006h")
 	defer fmt.Print("\x1b[?1006l")
-	fmt.Print("\x1b[?1002h")
-	defer fmt.Print("\x1b[?1002l")
+	// report all motion, so that it can be passed along to programs that
+	// ask for it
+	fmt.Print("\x1b[?1003h")
+	defer fmt.Print("\x1b[?1003l")
 
 	fmt.Print("\x1b[?1l")
 
+	clientID, err := randomIdentifier()
+	if err != nil {
+		return fmt.Errorf("Failed to generate client ID: %s", err)
+	}
+
 	fdConn, err := net.Dial("unix", sessionInfo.fdPath)
 	if err != nil {
 		return fmt.Errorf("Although the server socket exists, connection to it failed: %s", err)
@@ -47,28 +54,35 @@ func attach(sessionInfo *SessionInfo) error {
 		return fmt.Errorf("After connection to the server socket, processing failed: %s", err)
 	}
 
+	var flags byte
+	if readOnly {
+		flags |= fdFlagReadOnly
+	}
+
 	rights := syscall.UnixRights(int(os.Stdin.Fd()), int(os.Stdout.Fd()))
-	err = syscall.Sendmsg(int(fConn.Fd()), nil, rights, nil, 0)
+	err = syscall.Sendmsg(int(fConn.Fd()), append([]byte{flags}, clientID...), rights, nil, 0)
 	if err != nil {
 		return fmt.Errorf("Passing terminal control to the session server failed: %s", err)
 	}
 	fConn.Close()
 
-	defer net.Dial("unix", sessionInfo.detachPath)
+	defer requestDetach(sessionInfo, clientID)
 
 	go func() {
 		for {
 			c := make(chan os.Signal, 1)
 			signal.Notify(c, syscall.SIGWINCH)
 			<-c
-			updateSize(sessionInfo)
+			updateSize(sessionInfo, clientID)
 		}
 	}()
 
-	updateSize(sessionInfo)
+	updateSize(sessionInfo, clientID)
 
-	os.Remove(sessionInfo.killClientPath)
-	detachSocket, err := net.Listen("unix", sessionInfo.killClientPath)
+	killClientPath := sessionInfo.clientKillPath(clientID)
+	os.Remove(killClientPath)
+	defer os.Remove(killClientPath)
+	detachSocket, err := net.Listen("unix", killClientPath)
 	if err != nil {
 		return fmt.Errorf("Client shutdown scenario planning failed: %s", err)
 	}
@@ -95,7 +109,7 @@ func waitForFdSock(sessionInfo *SessionInfo) error {
 	return nil
 }
 
-func updateSize(sessionInfo *SessionInfo) {
+func updateSize(sessionInfo *SessionInfo, clientID string) {
 	w, h, _ := getTermSize()
 
 	conn, err := net.Dial("unix", sessionInfo.resizePath)
@@ -103,14 +117,30 @@ func updateSize(sessionInfo *SessionInfo) {
 		panic(err)
 	}
 
-	conn.Write([]byte{
+	conn.Write(append([]byte{
 		byte(w >> 8), byte(w % 256),
 		byte(h >> 8), byte(h % 256),
-	})
+	}, clientID...))
 
 	conn.Close()
 }
 
+// detachActive is sent by `3mux detach` in place of a client ID, since the
+// command can't tell which client typed it. It can't be mistaken for an ID,
+// which never contains a colon.
+const detachActive = ":active"
+
+// requestDetach asks the server to detach the given client. An empty client
+// ID detaches every client.
+func requestDetach(sessionInfo *SessionInfo, clientID string) {
+	conn, err := net.Dial("unix", sessionInfo.detachPath)
+	if err != nil {
+		return
+	}
+	conn.Write([]byte(clientID))
+	conn.Close()
+}
+
 func getTermSize() (int, int, error) {
 	cmd := exec.Command("stty", "size")
 	cmd.Stdin = os.Stdin
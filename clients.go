@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bufio"
+	"log"
+	"net"
+	"sync"
+
+	"github.com/aaronjanse/3mux/ecma48"
+	"github.com/npat-efault/poller"
+)
+
+// A client is a host terminal attached to the session
+type client struct {
+	id   string
+	w, h int
+
+	// readOnly clients see the session but cannot send it input
+	readOnly bool
+
+	stdin  chan ecma48.Output
+	poller *poller.FD
+	done   chan bool
+}
+
+// clientInput is a parsed chunk of stdin along with the client that sent it
+type clientInput struct {
+	client *client
+	ecma48.Output
+}
+
+// A clientSet tracks every client attached to a session
+type clientSet struct {
+	sessionInfo *SessionInfo
+	clients     map[string]*client
+	mutex       *sync.Mutex
+
+	// sizing is either "smallest" or "latest"
+	sizing     string
+	lastActive string
+}
+
+func newClientSet(sessionInfo *SessionInfo, sizing string) *clientSet {
+	return &clientSet{
+		sessionInfo: sessionInfo,
+		clients:     map[string]*client{},
+		mutex:       &sync.Mutex{},
+		sizing:      sizing,
+	}
+}
+
+// attach starts reading stdin from a newly connected client. onExit is called
+// when the client's stdin closes without a clean detach.
+func (cs *clientSet) attach(id string, readOnly bool, stdinFd int, input chan<- clientInput, onExit func()) *client {
+	stdinPoller, _ := poller.NewFD(stdinFd)
+	c := &client{
+		id:       id,
+		readOnly: readOnly,
+		stdin:    make(chan ecma48.Output, 64),
+		poller:   stdinPoller,
+		done:     make(chan bool),
+	}
+
+	cs.mutex.Lock()
+	cs.clients[id] = c
+	if !readOnly {
+		cs.lastActive = id
+	}
+	cs.mutex.Unlock()
+
+	parser := ecma48.NewParser(true)
+	go func() {
+		parser.Parse(bufio.NewReader(stdinPoller), c.stdin)
+		onExit()
+	}()
+
+	go func() {
+		for {
+			select {
+			case next := <-c.stdin:
+				input <- clientInput{client: c, Output: next}
+			case <-c.done:
+				return
+			}
+		}
+	}()
+
+	return c
+}
+
+// detach stops listening to a client and tells it to exit. It returns false
+// if no such client is attached.
+func (cs *clientSet) detach(id string) bool {
+	cs.mutex.Lock()
+	c, ok := cs.clients[id]
+	if ok {
+		delete(cs.clients, id)
+	}
+	cs.mutex.Unlock()
+
+	if !ok {
+		return false
+	}
+
+	close(c.done)
+	c.poller.Close()
+
+	_, err := net.Dial("unix", cs.sessionInfo.clientKillPath(id))
+	if err != nil {
+		log.Printf("Failed to notify client %s of detach: %s", id, err)
+	}
+	return true
+}
+
+// ids returns the IDs of all attached clients
+func (cs *clientSet) ids() []string {
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+
+	out := []string{}
+	for id := range cs.clients {
+		out = append(out, id)
+	}
+	return out
+}
+
+func (cs *clientSet) setSize(id string, w, h int) {
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+
+	if c, ok := cs.clients[id]; ok {
+		c.w = w
+		c.h = h
+	}
+}
+
+// markActive records which client most recently sent input. It returns true
+// if this changes the size of the session.
+func (cs *clientSet) markActive(id string) bool {
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+
+	changed := cs.sizing == "latest" && cs.lastActive != id
+	cs.lastActive = id
+	return changed
+}
+
+// hasReadOnly returns whether any read-only client is attached
+func (cs *clientSet) hasReadOnly() bool {
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+
+	for _, c := range cs.clients {
+		if c.readOnly {
+			return true
+		}
+	}
+	return false
+}
+
+// active returns the ID of the client that most recently sent input
+func (cs *clientSet) active() string {
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+
+	return cs.lastActive
+}
+
+// size returns the dimensions the session should be rendered at
+func (cs *clientSet) size() (w, h int, ok bool) {
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+
+	if cs.sizing == "latest" {
+		if c, found := cs.clients[cs.lastActive]; found && c.w > 0 && c.h > 0 {
+			return c.w, c.h, true
+		}
+	}
+
+	for _, c := range cs.clients {
+		if c.w == 0 || c.h == 0 {
+			continue // hasn't reported its size yet
+		}
+		if !ok || c.w < w {
+			w = c.w
+		}
+		if !ok || c.h < h {
+			h = c.h
+		}
+		ok = true
+	}
+	return
+}
@@ -12,6 +12,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/BurntSushi/toml"
 	"github.com/BurntSushi/xdg"
@@ -20,7 +21,9 @@ import (
 
 type UserConfig struct {
 	General *CompiledConfigGeneral
+	Theme   *ThemeConfig                      `toml:"theme"`
 	Keys    map[string][]string               `toml:"keys"`
+	Popups  map[string][]string               `toml:"popups"`
 	Modes   map[string]map[string]interface{} `toml:"modes"`
 }
 
@@ -28,15 +31,37 @@ type CompiledConfig struct {
 	modeStarters map[string]string // key -> mode name
 	isSticky     map[string]bool
 
-	normalBindings map[string]func(*wm.Universe)
-	modeBindings   map[string]map[string]func(*wm.Universe)
+	normalBindings map[string]func(*wm.Universe) error
+	modeBindings   map[string]map[string]func(*wm.Universe) error
 
 	generalSettings *CompiledConfigGeneral
 }
 
 type CompiledConfigGeneral struct {
-	EnableHelpBar   bool `toml:"enable-help-bar"`
-	EnableStatusBar bool `toml:"enable-status-bar"`
+	EnableHelpBar   bool   `toml:"enable-help-bar"`
+	EnableStatusBar bool   `toml:"enable-status-bar"`
+	SessionSize     string `toml:"session-size"`
+
+	CheckpointInterval   string   `toml:"checkpoint-interval"`
+	CheckpointScrollback bool     `toml:"checkpoint-scrollback"`
+	ResurrectCommands    []string `toml:"resurrect-commands"`
+
+	MainPaneRatio float64 `toml:"main-pane-ratio"`
+	AutoBalance   bool    `toml:"auto-balance"`
+	PaneTitles    bool    `toml:"pane-titles"`
+	InnerGap      int     `toml:"inner-gap"`
+	OuterGap      int     `toml:"outer-gap"`
+	ScrollLines   int     `toml:"scroll-lines"`
+
+	checkpointInterval time.Duration
+	theme              wm.Theme
+}
+
+// defaultResurrectCommands are the programs that `3mux resurrect` restarts
+// unless the config says otherwise. Anything else could have side effects.
+var defaultResurrectCommands = []string{
+	"vi", "vim", "nvim", "emacs", "nano", "man", "less", "more",
+	"tail", "top", "htop", "watch", "ssh",
 }
 
 func loadOrGenerateConfig() (*CompiledConfig, error) {
@@ -82,15 +107,73 @@ func loadOrGenerateConfig() (*CompiledConfig, error) {
 
 	conf.General.EnableHelpBar = conf.General.EnableHelpBar || firstRun
 
+	switch conf.General.SessionSize {
+	case "":
+		conf.General.SessionSize = "smallest"
+	case "smallest", "latest":
+	default:
+		return nil, fmt.Errorf("Invalid session-size `%s`: expected `smallest` or `latest`", conf.General.SessionSize)
+	}
+
+	if conf.General.CheckpointInterval == "" {
+		conf.General.CheckpointInterval = "30s"
+	}
+	interval, err := time.ParseDuration(conf.General.CheckpointInterval)
+	if err != nil || interval < 0 {
+		return nil, fmt.Errorf("Invalid checkpoint-interval `%s`: expected a duration like `30s`", conf.General.CheckpointInterval)
+	}
+	conf.General.checkpointInterval = interval
+
+	if conf.General.MainPaneRatio == 0 {
+		conf.General.MainPaneRatio = float64(wm.DefaultSettings.MainPaneRatio)
+	}
+	if conf.General.MainPaneRatio <= 0 || conf.General.MainPaneRatio >= 1 {
+		return nil, fmt.Errorf("Invalid main-pane-ratio `%v`: expected a number between 0 and 1", conf.General.MainPaneRatio)
+	}
+
+	if conf.General.InnerGap < 0 || conf.General.OuterGap < 0 {
+		return nil, fmt.Errorf("Invalid gaps: inner-gap and outer-gap cannot be negative")
+	}
+
+	if conf.General.ScrollLines == 0 {
+		conf.General.ScrollLines = wm.DefaultSettings.ScrollLines
+	}
+	if conf.General.ScrollLines < 0 {
+		return nil, fmt.Errorf("Invalid scroll-lines `%d`: expected a positive number", conf.General.ScrollLines)
+	}
+
+	theme, err := compileTheme(conf.Theme)
+	if err != nil {
+		return nil, err
+	}
+	conf.General.theme = theme
+
+	if conf.General.ResurrectCommands == nil {
+		conf.General.ResurrectCommands = defaultResurrectCommands
+	}
+
 	return compileConfig(conf)
 }
 
+// wmSettings returns the parts of the config that the window manager uses
+func (c *CompiledConfigGeneral) wmSettings() wm.Settings {
+	return wm.Settings{
+		MainPaneRatio: float32(c.MainPaneRatio),
+		AutoBalance:   c.AutoBalance,
+		PaneTitles:    c.PaneTitles,
+		InnerGap:      c.InnerGap,
+		OuterGap:      c.OuterGap,
+		ScrollLines:   c.ScrollLines,
+		Theme:         c.theme,
+	}
+}
+
 func compileConfig(user *UserConfig) (*CompiledConfig, error) {
 	conf := &CompiledConfig{
 		modeStarters:   map[string]string{},
 		isSticky:       map[string]bool{},
-		normalBindings: map[string]func(*wm.Universe){},
-		modeBindings:   map[string]map[string]func(*wm.Universe){},
+		normalBindings: map[string]func(*wm.Universe) error{},
+		modeBindings:   map[string]map[string]func(*wm.Universe) error{},
 	}
 	for modeName, mode := range user.Modes {
 		sticky, ok := mode["mode-sticky"]
@@ -121,6 +204,13 @@ func compileConfig(user *UserConfig) (*CompiledConfig, error) {
 	}
 
 	conf.normalBindings = compileBindings(user.Keys)
+	for command, keyCodes := range user.Popups {
+		command := command
+		fn := func(u *wm.Universe) error { return u.Popup(command) }
+		for _, keyCode := range keyCodes {
+			conf.normalBindings[strings.ToLower(keyCode)] = fn
+		}
+	}
 
 	conf.generalSettings = user.General
 
@@ -144,8 +234,8 @@ func castMapInterface(source map[string]interface{}) map[string][]string {
 	return out
 }
 
-func compileBindings(sourceBindings map[string][]string) map[string]func(*wm.Universe) {
-	compiledBindings := map[string]func(*wm.Universe){}
+func compileBindings(sourceBindings map[string][]string) map[string]func(*wm.Universe) error {
+	compiledBindings := map[string]func(*wm.Universe) error{}
 	for funcName, keyCodes := range sourceBindings {
 		fn, ok := wm.FuncNames[funcName]
 		if !ok {
@@ -172,7 +262,7 @@ func seiveConfigEvents(config *CompiledConfig, u *wm.Universe, human string) boo
 		}
 
 		if fn, ok := config.normalBindings[hu]; ok {
-			fn(u)
+			runBinding(fn, u, human)
 			return true
 		}
 	} else {
@@ -183,7 +273,7 @@ func seiveConfigEvents(config *CompiledConfig, u *wm.Universe, human string) boo
 		}
 
 		if fn, ok := bindings[hu]; ok {
-			fn(u)
+			runBinding(fn, u, human)
 			return true
 		}
 
@@ -192,11 +282,69 @@ func seiveConfigEvents(config *CompiledConfig, u *wm.Universe, human string) boo
 	return false
 }
 
+func runBinding(fn func(*wm.Universe) error, u *wm.Universe, human string) {
+	if err := fn(u); err != nil {
+		log.Printf("Binding for %s failed: %s", human, err)
+	}
+}
+
 const defaultConfig = `[general]
 
 enable-help-bar = false
 enable-status-bar = true
 
+# when several clients are attached, size the session to fit the
+# "smallest" client or follow the "latest" client to send input
+session-size = "smallest"
+
+# how often to save the session's layout for "3mux resurrect" ("0" disables)
+checkpoint-interval = "30s"
+# also save each pane's scrollback
+checkpoint-scrollback = false
+# programs that "3mux resurrect" restarts in their panes
+resurrect-commands = ["vi", "vim", "nvim", "emacs", "nano", "man", "less", "more", "tail", "top", "htop", "watch", "ssh"]
+
+# share of the workspace given to the main pane by the main-vertical and
+# main-horizontal layouts
+main-pane-ratio = 0.6
+
+# even out the sizes of all panes whenever one is added or closed
+auto-balance = false
+
+# draw each pane's title in the border above it, reserving the top row
+pane-titles = true
+
+# blank cells between panes, in place of divider lines, and around the edges
+# of the workspace
+inner-gap = 0
+outer-gap = 0
+
+# how far each turn of the scroll wheel scrolls
+scroll-lines = 5
+
+[theme]
+
+# "single", "rounded", "heavy", "double", or "ascii"
+border-chars = "single"
+
+# draw every pane but the selected one faintly
+dim-inactive = false
+
+# colors are names like "cyan" or "bright-black", numbers from 0 to 255, or
+# hex codes like "#ff8800". Styles can also set bold, faint, italic,
+# underline, and reverse.
+border            = { fg = "default" }
+active-border     = { fg = "cyan" }
+marked-border     = { fg = "magenta" }
+sync-border       = { fg = "red" }
+tab               = { fg = "white", bg = "bright-black" }
+active-tab        = { fg = "black", bg = "cyan" }
+status-bar        = { fg = "black", bg = "green" }
+status-bar-active = { fg = "white", bg = "black", bold = true }
+help-bar          = {}
+search-highlight  = { fg = "black", bg = "bright-green" }
+size-indicator    = { fg = "black", bg = "cyan" }
+
 [keys]
 
 new-pane  = ['Alt+N', 'Alt+Enter']
@@ -217,6 +365,48 @@ move-selection-down  = ['Alt+Down',  'Alt+J']
 move-selection-left  = ['Alt+Left',  'Alt+H']
 move-selection-right = ['Alt+Right', 'Alt+L']
 
+layout-tabbed       = ['Alt+W']
+layout-stacking     = ['Alt+S']
+layout-toggle-split = ['Alt+E']
+
+focus-parent = ['Alt+A']
+focus-child  = ['Alt+Shift+A']
+
+mark-pane        = ['Alt+M']
+swap-with-marked = ['Alt+Shift+M']
+
+toggle-floating       = ['Alt+F']
+toggle-floating-focus = ['Alt+O']
+
+scratchpad-toggle  = ['Alt+-']
+move-to-scratchpad = ['Alt+_']
+
+switch-workspace-1 = ['Alt+1']
+switch-workspace-2 = ['Alt+2']
+switch-workspace-3 = ['Alt+3']
+switch-workspace-4 = ['Alt+4']
+switch-workspace-5 = ['Alt+5']
+switch-workspace-6 = ['Alt+6']
+switch-workspace-7 = ['Alt+7']
+switch-workspace-8 = ['Alt+8']
+switch-workspace-9 = ['Alt+9']
+
+# these are Alt+Shift+1 through Alt+Shift+9 on a US keyboard
+move-pane-to-workspace-1 = ['Alt+!']
+move-pane-to-workspace-2 = ['Alt+@']
+move-pane-to-workspace-3 = ['Alt+#']
+move-pane-to-workspace-4 = ['Alt+$']
+move-pane-to-workspace-5 = ['Alt+%']
+move-pane-to-workspace-6 = ['Alt+^']
+move-pane-to-workspace-7 = ['Alt+&']
+move-pane-to-workspace-8 = ['Alt+*']
+move-pane-to-workspace-9 = ['Alt+(']
+
+# run COMMAND in a floating pane that closes once COMMAND exits
+[popups]
+# "COMMAND" = ['KEYCODE']
+# "lazygit" = ['Alt+G']
+
 # NAME has no meaning apart from what may be displayed in a status bar
 # [modes.NAME]
 # mode-start = ['KEYCODE'] # type KEYCODE to start this mode
@@ -240,6 +430,7 @@ resize-up    = ['Up',    'j']
 resize-down  = ['Down',  'k']
 resize-left  = ['Left',  'h']
 resize-right = ['Right', 'l']
+balance-splits = ['=']
 
 [modes.tmux]
 mode-start  = ['Ctrl+B']
@@ -249,6 +440,9 @@ split-pane-vert  = ['%']
 split-pane-horiz = ['"']
 move-pane-left   = ['{']
 move-pane-right  = ['}']
+cycle-layout     = [' ']
+focus-last-pane  = [';']
+break-pane       = ['!']
 
 # [modes.screen]
 # mode-start  = ['Ctrl+A']
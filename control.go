@@ -0,0 +1,327 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aaronjanse/3mux/ecma48"
+	"github.com/aaronjanse/3mux/pane"
+	"github.com/aaronjanse/3mux/wm"
+)
+
+/*
+The control socket lets external programs drive a running session. Each
+connection carries newline-delimited JSON. Every request is answered by
+exactly one reply, in order:
+
+	-> {"version": 1, "command": "split-pane-vert"}
+	<- {"version": 1, "ok": true}
+	-> {"version": 1, "command": "no-such-thing"}
+	<- {"version": 1, "ok": false, "error": "unknown command: no-such-thing"}
+
+Any action usable in a keybinding is also a command. See controlCommands for
+the commands that take arguments or return results.
+*/
+
+// controlVersion is the version of the control protocol this build speaks
+const controlVersion = 1
+
+type controlRequest struct {
+	Version int      `json:"version"`
+	Command string   `json:"command"`
+	Args    []string `json:"args,omitempty"`
+}
+
+type controlReply struct {
+	Version int         `json:"version"`
+	OK      bool        `json:"ok"`
+	Error   string      `json:"error,omitempty"`
+	Result  interface{} `json:"result,omitempty"`
+}
+
+// pendingControl is a request waiting to be run on the server's main loop
+type pendingControl struct {
+	controlRequest
+	reply chan controlReply
+}
+
+type controlFunc func(u *wm.Universe, args []string) (interface{}, error)
+
+// controlCommands are commands that only make sense over the control socket
+var controlCommands = map[string]controlFunc{
+	"version": func(u *wm.Universe, args []string) (interface{}, error) {
+		return controlVersion, nil
+	},
+	"get-tree": func(u *wm.Universe, args []string) (interface{}, error) {
+		return u.Serialize(), nil
+	},
+	"list-panes": func(u *wm.Universe, args []string) (interface{}, error) {
+		return u.ListPanes(), nil
+	},
+	"get-layout": func(u *wm.Universe, args []string) (interface{}, error) {
+		return u.Layout(false), nil
+	},
+	// send-keys [-l] <pane> <key>...
+	// Each key is a name like those in keybindings (e.g. "Ctrl+C" or "Enter")
+	// or else literal text. With -l, every argument is literal text.
+	"send-keys": func(u *wm.Universe, args []string) (interface{}, error) {
+		literal := len(args) > 0 && args[0] == "-l"
+		if literal {
+			args = args[1:]
+		}
+		if len(args) < 2 {
+			return nil, errors.New("usage: send-keys [-l] <pane> <key>...")
+		}
+		id, err := parsePaneID(args[0])
+		if err != nil {
+			return nil, err
+		}
+
+		in := []ecma48.Output{}
+		for _, key := range args[1:] {
+			if !literal {
+				x, ok, err := dehumanify(key)
+				if err != nil {
+					return nil, err
+				}
+				if ok {
+					in = append(in, x)
+					continue
+				}
+			}
+			in = append(in, literalInput(key)...)
+		}
+		return nil, u.SendToPane(id, in)
+	},
+	// popup <command>...
+	// The command runs in a floating pane that closes once it exits.
+	"popup": func(u *wm.Universe, args []string) (interface{}, error) {
+		if len(args) == 0 {
+			return nil, errors.New("usage: popup <command>...")
+		}
+		return nil, u.Popup(strings.Join(args, " "))
+	},
+	// capture-pane [-e] [-S start] [-E end] <pane>
+	// Lines are numbered from 0 at the top of the screen, with negative
+	// numbers reaching back into scrollback. "-" means the start of
+	// scrollback for -S and the bottom of the screen for -E.
+	"capture-pane": func(u *wm.Universe, args []string) (interface{}, error) {
+		flags := flag.NewFlagSet("capture-pane", flag.ContinueOnError)
+		flags.SetOutput(ioutil.Discard)
+		styled := flags.Bool("e", false, "keep colors and other styling")
+		startStr := flags.String("S", "0", "first line")
+		endStr := flags.String("E", "-", "last line")
+		if err := flags.Parse(args); err != nil || flags.NArg() != 1 {
+			return nil, errors.New("usage: capture-pane [-e] [-S start] [-E end] <pane>")
+		}
+
+		id, err := parsePaneID(flags.Arg(0))
+		if err != nil {
+			return nil, err
+		}
+		n, err := u.FindPane(id)
+		if err != nil {
+			return nil, err
+		}
+		p, ok := n.(*pane.Pane)
+		if !ok {
+			return nil, fmt.Errorf("pane %d cannot be captured", id)
+		}
+
+		scrollbackLen, screenLen := p.LineCount()
+		start, err := parseLineNumber(*startStr, -scrollbackLen)
+		if err != nil {
+			return nil, err
+		}
+		end, err := parseLineNumber(*endStr, screenLen-1)
+		if err != nil {
+			return nil, err
+		}
+		return p.Capture(start, end, *styled), nil
+	},
+}
+
+// parseLineNumber parses a line number for capture-pane, where "-" stands for
+// the given default
+func parseLineNumber(s string, dash int) (int, error) {
+	if s == "-" {
+		return dash, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid line number: %s", s)
+	}
+	return n, nil
+}
+
+// parsePaneID accepts pane IDs as listed by list-panes, optionally prefixed
+// with a percent sign like in tmux
+func parsePaneID(s string) (int, error) {
+	id, err := strconv.Atoi(strings.TrimPrefix(s, "%"))
+	if err != nil {
+		return 0, fmt.Errorf("invalid pane ID: %s", s)
+	}
+	return id, nil
+}
+
+func init() {
+	// registered here since it refers to controlCommands itself
+	controlCommands["list-commands"] = func(u *wm.Universe, args []string) (interface{}, error) {
+		names := []string{}
+		for name := range controlCommands {
+			names = append(names, name)
+		}
+		for name := range wm.FuncNames {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return names, nil
+	}
+}
+
+func runControlRequest(u *wm.Universe, req controlRequest) controlReply {
+	reply := controlReply{Version: controlVersion}
+
+	if req.Version > controlVersion {
+		reply.Error = fmt.Sprintf("unsupported protocol version %d (server speaks %d)", req.Version, controlVersion)
+		return reply
+	}
+
+	var result interface{}
+	var err error
+	if fn, ok := controlCommands[req.Command]; ok {
+		result, err = fn(u, req.Args)
+	} else if fn, ok := wm.FuncNames[req.Command]; ok {
+		if len(req.Args) > 0 {
+			err = fmt.Errorf("%s takes no arguments", req.Command)
+		} else {
+			err = fn(u)
+		}
+	} else {
+		err = fmt.Errorf("unknown command: %s", req.Command)
+	}
+
+	if err != nil {
+		reply.Error = err.Error()
+	} else {
+		reply.OK = true
+		reply.Result = result
+	}
+	return reply
+}
+
+// listenControl accepts control connections, passing each request to the
+// given channel and writing back its reply
+func listenControl(sessionInfo *SessionInfo, requests chan<- pendingControl) {
+	socket, err := net.Listen("unix", sessionInfo.controlPath)
+	if err != nil {
+		panic(err)
+	}
+
+	go func() {
+		for {
+			conn, err := socket.Accept()
+			if err != nil {
+				panic(err)
+			}
+			go serveControlConn(conn, requests)
+		}
+	}()
+}
+
+func serveControlConn(conn net.Conn, requests chan<- pendingControl) {
+	defer conn.Close()
+
+	decoder := json.NewDecoder(bufio.NewReader(conn))
+	encoder := json.NewEncoder(conn)
+	for {
+		var req controlRequest
+		err := decoder.Decode(&req)
+		if err != nil {
+			if err != io.EOF {
+				log.Println("Control decode error:", err)
+				encoder.Encode(controlReply{
+					Version: controlVersion,
+					Error:   fmt.Sprintf("malformed request: %s", err),
+				})
+			}
+			return
+		}
+
+		pending := pendingControl{controlRequest: req, reply: make(chan controlReply, 1)}
+		requests <- pending
+		err = encoder.Encode(<-pending.reply)
+		if err != nil {
+			log.Println("Control encode error:", err)
+			return
+		}
+	}
+}
+
+// sendControl sends a single request to a session and waits for its reply
+func sendControl(sessionInfo *SessionInfo, command string, args []string) (controlReply, error) {
+	conn, err := net.Dial("unix", sessionInfo.controlPath)
+	if err != nil {
+		return controlReply{}, fmt.Errorf("Failed to connect to session control socket: %s", err)
+	}
+	defer conn.Close()
+
+	err = json.NewEncoder(conn).Encode(controlRequest{
+		Version: controlVersion,
+		Command: command,
+		Args:    args,
+	})
+	if err != nil {
+		return controlReply{}, fmt.Errorf("Failed to send request: %s", err)
+	}
+
+	var reply controlReply
+	err = json.NewDecoder(conn).Decode(&reply)
+	if err != nil {
+		return controlReply{}, fmt.Errorf("Failed to read reply: %s", err)
+	}
+	return reply, nil
+}
+
+// runMsg implements `3mux msg`, printing the result of a control command
+func runMsg(sessionName string, command string, args []string) error {
+	sessionInfo, found, err := findSession(sessionName)
+	if err != nil {
+		return fmt.Errorf("Error while querying sessions: %s", err)
+	}
+	if !found {
+		return fmt.Errorf("Failed to find session with name: %s", sessionName)
+	}
+
+	reply, err := sendControl(sessionInfo, command, args)
+	if err != nil {
+		return err
+	}
+	if !reply.OK {
+		return errors.New(reply.Error)
+	}
+
+	switch result := reply.Result.(type) {
+	case nil:
+	case string:
+		fmt.Print(result)
+		if result != "" && !strings.HasSuffix(result, "\n") {
+			fmt.Println()
+		}
+	default:
+		out, _ := json.MarshalIndent(result, "", "  ")
+		os.Stdout.Write(append(out, '\n'))
+	}
+	return nil
+}
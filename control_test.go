@@ -0,0 +1,29 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestParseLineNumber(t *testing.T) {
+	tests := []struct {
+		in   string
+		dash int
+		n    int
+		err  bool
+	}{
+		{in: "0", dash: -50, n: 0},
+		{in: "12", dash: -50, n: 12},
+		{in: "-20", dash: -50, n: -20},
+		{in: "-", dash: -50, n: -50},
+		{in: "-", dash: 23, n: 23},
+		{in: "", err: true},
+		{in: "top", err: true},
+	}
+
+	for _, tt := range tests {
+		n, err := parseLineNumber(tt.in, tt.dash)
+		if (err != nil) != tt.err || n != tt.n {
+			t.Errorf("parseLineNumber(%q, %d) = %d, %v", tt.in, tt.dash, n, err)
+		}
+	}
+}
@@ -65,6 +65,14 @@ type CursorMovement struct {
 	Ctrl  bool
 }
 
+// WindowTitle is sent by OSC 0, 1, and 2, which set the icon name, the window
+// title, or both
+type WindowTitle struct {
+	Title      string
+	IconName   bool
+	WindowName bool
+}
+
 // RI (Reverse Index)
 type RI struct{}
 
@@ -177,18 +185,54 @@ type StyleForeground Color
 
 type StyleBackground Color
 
-type ScrollDown int
+// A MouseButton is a mouse button, numbered as in xterm's mouse reporting
+type MouseButton int
 
-type ScrollUp int
+// mouse buttons
+const (
+	LeftButton MouseButton = iota
+	MiddleButton
+	RightButton
+)
 
-type MouseDown struct {
+// MouseMods are the modifier keys held during a mouse event
+type MouseMods struct {
+	Shift, Alt, Ctrl bool
+}
+
+// ScrollDown is the scroll wheel turning up, which moves the contents down
+type ScrollDown struct {
 	X, Y int
+	MouseMods
 }
 
-type MouseUp struct {
+// ScrollUp is the scroll wheel turning down, which moves the contents up
+type ScrollUp struct {
 	X, Y int
+	MouseMods
+}
+
+type MouseDown struct {
+	X, Y   int
+	Button MouseButton
+	MouseMods
 }
 
+type MouseUp struct {
+	X, Y   int
+	Button MouseButton
+	MouseMods
+}
+
+// MouseDrag is the mouse moving while a button is held
 type MouseDrag struct {
+	X, Y   int
+	Button MouseButton
+	MouseMods
+}
+
+// MouseMove is the mouse moving with no button held
+type MouseMove struct {
 	X, Y int
+	MouseMods
 }
@@ -33,6 +33,9 @@ type Parser struct {
 
 	data []rune
 
+	// osc holds the text of an OSC string until it is terminated
+	osc []rune
+
 	// RuneCounter is useful for detecting if the processer is lagging
 	RuneCounter uint64
 
@@ -50,6 +53,7 @@ func NewParser(keyboardMode bool) *Parser {
 		params:       "",
 		final:        0,
 		data:         []rune{},
+		osc:          []rune{},
 		RuneCounter:  0,
 		Shutdown:     make(chan error),
 		isDead:       false,
@@ -178,6 +182,10 @@ func (p *Parser) anywhere(r rune) {
 	switch r {
 	case 0x00:
 	case 0x1B:
+		if p.state == stateOscString {
+			// ESC \ (ST) ends the string; the backslash is then ignored
+			p.dispatchOsc()
+		}
 		p.doClear()
 		p.state = stateEscape
 	case 0x8D: // Reverse Index
@@ -187,8 +195,12 @@ func (p *Parser) anywhere(r rune) {
 		p.doClear()
 		p.state = stateCsiEntry
 	case 0x9C:
+		if p.state == stateOscString {
+			p.dispatchOsc()
+		}
 		p.state = stateGround
 	case 0x9D:
+		p.doClear()
 		p.state = stateOscString
 	default:
 		switch p.state {
@@ -299,15 +311,39 @@ func (p *Parser) stateCsiParam(r rune) {
 }
 
 func (p *Parser) stateOscString(r rune) {
-	// TODO: https://askubuntu.com/a/1141814
 	switch {
-	case 0x07 == r: // FIXME: this is weird
-		p.out <- p.wrap(Unrecognized("OSC"))
+	case 0x07 == r: // xterm accepts BEL in place of ST
+		p.dispatchOsc()
 		p.state = stateGround
+	case r >= 0x20:
+		p.osc = append(p.osc, r)
+	}
+}
+
+// dispatchOsc handles an OSC string of the form `Ps ; Pt`
+func (p *Parser) dispatchOsc() {
+	parts := strings.SplitN(string(p.osc), ";", 2)
+	p.osc = []rune{}
+	if len(parts) != 2 {
+		p.out <- p.wrap(Unrecognized("OSC"))
+		return
+	}
+
+	switch parts[0] {
+	case "0", "1", "2":
+		code, _ := strconv.Atoi(parts[0])
+		p.out <- p.wrap(WindowTitle{
+			Title:      parts[1],
+			IconName:   code == 0 || code == 1,
+			WindowName: code == 0 || code == 2,
+		})
+	default:
+		p.out <- p.wrap(Unrecognized("OSC"))
 	}
 }
 
 func (p *Parser) doClear() {
+	p.osc = []rune{}
 	p.private = 0
 	p.intermediate = ""
 	p.params = ""
@@ -317,47 +353,56 @@ func (p *Parser) doClear() {
 func (p *Parser) dispatchCsi() {
 	// fmt.Printf("\r\x1b[K? CSI %s %s", p.params, string(p.final))
 	switch p.intermediate {
-	case "<":
+	case "<": // SGR mouse reporting
 		seq := parseSemicolonNumSeq(p.params, 1)
+		if len(seq) < 3 {
+			p.out <- p.wrap(Unrecognized("Mouse"))
+			break
+		}
 
-		switch seq[0] {
-		case 0:
-			if len(seq) > 2 {
-				switch p.final {
-				case 'M':
-					p.out <- p.wrap(MouseDown{X: seq[1] - 1, Y: seq[2] - 1})
-				case 'm':
-					p.out <- p.wrap(MouseUp{X: seq[1] - 1, Y: seq[2] - 1})
-				default:
-					p.out <- p.wrap(Unrecognized("Mouse"))
-				}
-			}
-		case 32:
-			if len(seq) > 2 {
-				p.out <- p.wrap(MouseDrag{X: seq[1] - 1, Y: seq[2] - 1})
-			}
-		case 64:
-			p.out <- p.wrap(ScrollDown(1))
-		case 65:
-			p.out <- p.wrap(ScrollUp(1))
+		x, y := seq[1]-1, seq[2]-1
+		mods := MouseMods{
+			Shift: seq[0]&4 > 0,
+			Alt:   seq[0]&8 > 0,
+			Ctrl:  seq[0]&16 > 0,
+		}
+		button := MouseButton(seq[0] & 3)
+		switch code := seq[0] &^ (4 | 8 | 16 | 3); {
+		case code == 64 && button == 0:
+			p.out <- p.wrap(ScrollDown{X: x, Y: y, MouseMods: mods})
+		case code == 64 && button == 1:
+			p.out <- p.wrap(ScrollUp{X: x, Y: y, MouseMods: mods})
+		case code == 32 && button == 3:
+			p.out <- p.wrap(MouseMove{X: x, Y: y, MouseMods: mods})
+		case code == 32:
+			p.out <- p.wrap(MouseDrag{X: x, Y: y, Button: button, MouseMods: mods})
+		case code == 0 && button != 3 && p.final == 'M':
+			p.out <- p.wrap(MouseDown{X: x, Y: y, Button: button, MouseMods: mods})
+		case code == 0 && button != 3 && p.final == 'm':
+			p.out <- p.wrap(MouseUp{X: x, Y: y, Button: button, MouseMods: mods})
 		default:
-			// p.out <- p.wrap(Unrecognized("Mouse"))
+			p.out <- p.wrap(Unrecognized("Mouse"))
 		}
 	case "?":
 		switch p.final {
 		case 'h': // DECSET
-			i, err := strconv.Atoi(p.params)
-			if err == nil {
-				p.out <- p.wrap(PrivateDEC{On: true, Code: i})
-			} else {
-				p.out <- p.wrap(Unrecognized("DECSET"))
+			// several modes can be set at once, like `CSI ? 1002 ; 1006 h`
+			for _, param := range strings.Split(p.params, ";") {
+				i, err := strconv.Atoi(param)
+				if err == nil {
+					p.out <- p.wrap(PrivateDEC{On: true, Code: i})
+				} else {
+					p.out <- p.wrap(Unrecognized("DECSET"))
+				}
 			}
 		case 'l': // DECRST
-			i, err := strconv.Atoi(p.params)
-			if err == nil {
-				p.out <- p.wrap(PrivateDEC{On: false, Code: i})
-			} else {
-				p.out <- p.wrap(Unrecognized("DECRST"))
+			for _, param := range strings.Split(p.params, ";") {
+				i, err := strconv.Atoi(param)
+				if err == nil {
+					p.out <- p.wrap(PrivateDEC{On: false, Code: i})
+				} else {
+					p.out <- p.wrap(Unrecognized("DECRST"))
+				}
 			}
 		default:
 			p.out <- p.wrap(Unrecognized("DEC Private Mode"))
@@ -0,0 +1,123 @@
+package ecma48
+
+import (
+	"bufio"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// parseAll runs a parser over the input and returns everything it parsed
+func parseAll(keyboardMode bool, input string) []Parsed {
+	out := make(chan Output)
+	done := make(chan struct{})
+	p := NewParser(keyboardMode)
+	go func() {
+		p.Parse(bufio.NewReader(strings.NewReader(input)), out)
+		close(done)
+	}()
+
+	parsed := []Parsed{}
+	for {
+		select {
+		case o := <-out:
+			parsed = append(parsed, o.Parsed)
+		case <-done:
+			return parsed
+		}
+	}
+}
+
+func TestParseOSC(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []Parsed
+	}{
+		{
+			name:  "window title ended by BEL",
+			input: "\x1b]2;vim\x07",
+			want:  []Parsed{WindowTitle{Title: "vim", WindowName: true}},
+		},
+		{
+			name:  "window title ended by ST",
+			input: "\x1b]2;vim\x1b\\",
+			want:  []Parsed{WindowTitle{Title: "vim", WindowName: true}},
+		},
+		{
+			name:  "icon name and title",
+			input: "\x1b]0;~/src: bash\x07",
+			want:  []Parsed{WindowTitle{Title: "~/src: bash", IconName: true, WindowName: true}},
+		},
+		{
+			name:  "icon name only",
+			input: "\x1b]1;bash\x07",
+			want:  []Parsed{WindowTitle{Title: "bash", IconName: true}},
+		},
+		{
+			name:  "title with semicolons",
+			input: "\x1b]2;a;b\x07",
+			want:  []Parsed{WindowTitle{Title: "a;b", WindowName: true}},
+		},
+		{
+			name:  "empty title",
+			input: "\x1b]2;\x07",
+			want:  []Parsed{WindowTitle{WindowName: true}},
+		},
+		{
+			name:  "8-bit OSC and ST",
+			input: "\u009d2;top\u009c",
+			want:  []Parsed{WindowTitle{Title: "top", WindowName: true}},
+		},
+		{
+			name:  "followed by text",
+			input: "\x1b]2;x\x07hi",
+			want:  []Parsed{WindowTitle{Title: "x", WindowName: true}, Char{Rune: 'h'}, Char{Rune: 'i'}},
+		},
+		{
+			name:  "other OSC",
+			input: "\x1b]52;c;aGk=\x07",
+			want:  []Parsed{Unrecognized("OSC")},
+		},
+		{
+			name:  "no parameter",
+			input: "\x1b]vim\x07",
+			want:  []Parsed{Unrecognized("OSC")},
+		},
+	}
+
+	for _, tt := range tests {
+		got := parseAll(false, tt.input)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: parsed %q as %#v, want %#v", tt.name, tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestParseSGRMouse(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Parsed
+	}{
+		{input: "\x1b[<0;5;3M", want: MouseDown{X: 4, Y: 2, Button: LeftButton}},
+		{input: "\x1b[<2;1;1M", want: MouseDown{X: 0, Y: 0, Button: RightButton}},
+		{input: "\x1b[<1;10;20m", want: MouseUp{X: 9, Y: 19, Button: MiddleButton}},
+		{input: "\x1b[<32;7;8M", want: MouseDrag{X: 6, Y: 7, Button: LeftButton}},
+		{input: "\x1b[<35;7;8M", want: MouseMove{X: 6, Y: 7}},
+		{input: "\x1b[<64;3;4M", want: ScrollDown{X: 2, Y: 3}},
+		{input: "\x1b[<65;3;4M", want: ScrollUp{X: 2, Y: 3}},
+		{input: "\x1b[<4;2;2M", want: MouseDown{X: 1, Y: 1, MouseMods: MouseMods{Shift: true}}},
+		{input: "\x1b[<24;2;2M", want: MouseDown{X: 1, Y: 1, MouseMods: MouseMods{Alt: true, Ctrl: true}}},
+		{input: "\x1b[<80;2;2M", want: ScrollDown{X: 1, Y: 1, MouseMods: MouseMods{Ctrl: true}}},
+		{input: "\x1b[<0;5M", want: Unrecognized("Mouse")},
+		{input: "\x1b[<3;5;3M", want: Unrecognized("Mouse")},
+		{input: "\x1b[<128;5;3M", want: Unrecognized("Mouse")},
+	}
+
+	for _, tt := range tests {
+		got := parseAll(true, tt.input)
+		if len(got) != 1 || !reflect.DeepEqual(got[0], tt.want) {
+			t.Errorf("parsed %q as %#v, want %#v", tt.input, got, tt.want)
+		}
+	}
+}
@@ -77,7 +77,7 @@ func fuzzVTerm() {
 	}()
 
 	r := &FakeRenderer{}
-	p := pane.NewPane(r, false, "1")
+	p := pane.NewPane(r, false, "1", wm.DefaultTheme, wm.PaneSpec{})
 	p.SetDeathHandler(func(err error) {
 		panic(err)
 	})
@@ -115,7 +115,7 @@ func fuzzWM() {
 	r := &FakeRenderer{}
 	for {
 		var stop bool
-		u := wm.NewUniverse(r, false, false, func(err error) {
+		u := wm.NewUniverse(r, false, false, wm.DefaultSettings, func(err error) {
 			stop = true
 		}, wm.Rect{W: 100, H: 100}, newFakePane)
 		pastStates = []string{}
@@ -155,7 +155,7 @@ func fuzzWM() {
 	}
 }
 
-func getRandomFunc() (string, func(*wm.Universe)) {
+func getRandomFunc() (string, func(*wm.Universe) error) {
 	i := mathRand.Intn(len(wm.FuncNames))
 	for k, v := range wm.FuncNames {
 		if i == 0 {
@@ -174,12 +174,22 @@ func (r *FakeRenderer) SetCursor(x, y int) {
 }
 
 type FakePane struct {
+	id   int
 	rect wm.Rect
 	dead bool
 }
 
-func newFakePane(renderer ecma48.Renderer) wm.Node {
-	return &FakePane{}
+var fakePaneCount int
+
+func newFakePane(renderer ecma48.Renderer, spec wm.PaneSpec) wm.Node {
+	fakePaneCount++
+	return &FakePane{id: fakePaneCount}
+}
+func (p *FakePane) ID() int {
+	return p.id
+}
+func (p *FakePane) Spec(history bool) wm.PaneSpec {
+	return wm.PaneSpec{}
 }
 func (p *FakePane) SetRenderRect(fullscreen bool, x, y, w, h int) {
 	p.rect = wm.Rect{X: x, Y: y, W: w, H: h}
@@ -190,6 +200,13 @@ func (p *FakePane) GetRenderRect() wm.Rect {
 func (p *FakePane) Serialize() string {
 	return "FakePane"
 }
+func (p *FakePane) Title() string {
+	return "FakePane"
+}
+func (p *FakePane) SetHidden(hidden bool) {
+}
+func (p *FakePane) SetCovered(rects []wm.Rect) {
+}
 func (p *FakePane) SetPaused(paused bool) {
 }
 func (p *FakePane) Kill() {
@@ -198,9 +215,9 @@ func (p *FakePane) Kill() {
 func (p *FakePane) IsDead() bool {
 	return p.dead
 }
-func (p *FakePane) ScrollUp() {
+func (p *FakePane) ScrollUp(lines int) {
 }
-func (p *FakePane) ScrollDown() {
+func (p *FakePane) ScrollDown(lines int) {
 }
 func (p *FakePane) ToggleSearch() {
 }
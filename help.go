@@ -3,19 +3,29 @@ package main
 import "fmt"
 
 func showHelp() {
-	fmt.Println(helpText)
+	fmt.Print(helpText)
 }
 
 const helpText = `3mux
 The terminal multiplexer inspired by i3
 
 USAGE:
-    3mux                  Interactive 3mux interface
-    3mux ls               List session names (has alias '3mux ps')
-    3mux attach <name>    Attach to a session
-    3mux detach           Detach from the current session
-    3mux new <name>       Create a new session
-    3mux kill <name>      Kill a session
+    3mux                             Interactive 3mux interface
+    3mux ls                          List session names (has alias '3mux ps')
+    3mux attach <name>               Attach to a session
+    3mux attach --read-only <name>   Watch a session without sending it input
+    3mux detach                      Detach from the current session
+    3mux detach --all                Detach every client from the current session
+    3mux new <name>                  Create a new session
+    3mux new <name> --layout <file>  Create a new session from a saved layout
+    3mux kill <name>                 Kill a session
+    3mux msg <name> <command>        Run a command (e.g. split-pane-vert) in a session
+    3mux send-keys <name> <pane> <key>...
+                                     Type into a pane (see '3mux msg <name> list-panes')
+    3mux capture-pane <name> [-e] [-S start] [-E end] <pane>
+                                     Print a pane's screen and scrollback
+    3mux save-layout <name> [file]   Save a session's layout for use with '3mux new'
+    3mux resurrect <name>            Recreate a session whose server died
 
 SHORTCUTS:
 	Alt+N/Alt+Enter   Create new pane
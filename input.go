@@ -2,28 +2,39 @@ package main
 
 import (
 	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
 
 	"github.com/aaronjanse/3mux/ecma48"
 	"github.com/aaronjanse/3mux/wm"
 )
 
-var mouseDownX, mouseDownY int
-
 // seiveMouseEvents processes mouse events and returns true if the data should *not* be passed downstream
 func seiveMouseEvents(u *wm.Universe, human string, obj ecma48.Output) bool {
+	if ev, ok := obj.Parsed.(ecma48.MouseDown); ok {
+		u.SelectAtCoords(ev.X, ev.Y)
+	}
+	// programs that asked for the mouse get it, except on borders
+	if u.ForwardMouse(obj.Parsed) {
+		return true
+	}
+
 	switch ev := obj.Parsed.(type) {
 	case ecma48.MouseDown:
-		u.SelectAtCoords(ev.X, ev.Y)
-		mouseDownX = ev.X
-		mouseDownY = ev.Y
+		if ev.Button == ecma48.LeftButton {
+			u.BeginDrag(ev.X, ev.Y, ev.Alt)
+		}
 	case ecma48.MouseUp:
-		u.DragBorder(mouseDownX, mouseDownY, ev.X, ev.Y)
+		u.EndDrag(ev.X, ev.Y)
 	case ecma48.MouseDrag:
-		// do nothing
+		u.DragTo(ev.X, ev.Y)
+	case ecma48.MouseMove:
+		// only programs care about this
 	case ecma48.ScrollUp:
-		u.ScrollUp()
+		u.ScrollUp(ev.X, ev.Y)
 	case ecma48.ScrollDown:
-		u.ScrollDown()
+		u.ScrollDown(ev.X, ev.Y)
 	default:
 		return false
 	}
@@ -76,3 +87,174 @@ func humanifyRune(r rune) string {
 		return string(r)
 	}
 }
+
+// dehumanify is the inverse of humanify. It turns a key name such as
+// "Ctrl+C", "Alt+Shift+Up", or "Enter" into the input that key would produce.
+// It returns false if key isn't a key name, and an error if it names a key
+// that has no encoding.
+func dehumanify(key string) (ecma48.Output, bool, error) {
+	var ctrl, alt, shift bool
+	name := key
+	for {
+		lower := strings.ToLower(name)
+		if strings.HasPrefix(lower, "ctrl+") && len(name) > 5 {
+			ctrl = true
+			name = name[5:]
+		} else if strings.HasPrefix(lower, "alt+") && len(name) > 4 {
+			alt = true
+			name = name[4:]
+		} else if strings.HasPrefix(lower, "shift+") && len(name) > 6 {
+			shift = true
+			name = name[6:]
+		} else {
+			break
+		}
+	}
+
+	directions := map[string]ecma48.Direction{
+		"up": ecma48.Up, "down": ecma48.Down, "left": ecma48.Left, "right": ecma48.Right,
+	}
+	if dir, ok := directions[strings.ToLower(name)]; ok {
+		finals := map[ecma48.Direction]rune{
+			ecma48.Up: 'A', ecma48.Down: 'B', ecma48.Right: 'C', ecma48.Left: 'D',
+		}
+		raw := "\x1b[" + string(finals[dir])
+		if mod := modifierParam(ctrl, alt, shift); mod > 1 {
+			raw = fmt.Sprintf("\x1b[1;%d%c", mod, finals[dir])
+		}
+		return ecma48.Output{
+			Raw: []rune(raw),
+			Parsed: ecma48.CursorMovement{
+				Direction: dir, N: 1, Ctrl: ctrl, Alt: alt, Shift: shift,
+			},
+		}, true, nil
+	}
+
+	if k, ok := functionKeys[strings.ToLower(name)]; ok {
+		// the parser doesn't name these keys either
+		return ecma48.Output{Raw: []rune(k.encode(modifierParam(ctrl, alt, shift))), Parsed: ecma48.Unrecognized("CSI")}, true, nil
+	}
+	if functionKeyName.MatchString(name) {
+		return ecma48.Output{}, true, fmt.Errorf("unknown key `%s`", key)
+	}
+
+	var r rune
+	var parsed ecma48.Parsed
+	switch strings.ToLower(name) {
+	case "enter", "return":
+		r = '\r'
+		parsed = ecma48.CarriageReturn{}
+	case "tab":
+		if shift && !ctrl && !alt {
+			// backtab, which the parser doesn't name either
+			return ecma48.Output{Raw: []rune("\x1b[Z"), Parsed: ecma48.Unrecognized("CSI")}, true, nil
+		}
+		r = '\t'
+		parsed = ecma48.Tab{}
+	case "escape", "esc":
+		r = 27
+		parsed = ecma48.Esc{}
+	case "backspace":
+		r = 127
+		parsed = ecma48.Backspace{}
+	case "space":
+		r = ' '
+		parsed = ecma48.Char{Rune: r}
+	default:
+		runes := []rune(name)
+		if !ctrl && !alt && !shift {
+			return ecma48.Output{}, false, nil
+		}
+		if len(runes) != 1 {
+			return ecma48.Output{}, true, fmt.Errorf("unknown key `%s`", key)
+		}
+		r = runes[0]
+		parsed = ecma48.Char{Rune: r}
+	}
+
+	switch {
+	case ctrl:
+		// Ctrl+Shift+<letter> sends the same control character as Ctrl+<letter>
+		upper := unicode.ToUpper(r)
+		if upper < 'A' || upper > 'Z' {
+			return ecma48.Output{}, true, fmt.Errorf("cannot encode `%s`: Ctrl only combines with letters", key)
+		}
+		c := upper - 'A' + 1
+		if alt {
+			// this is what the parser makes of ESC followed by a control character
+			return ecma48.Output{Raw: []rune{27, c}, Parsed: ecma48.AltChar{Char: c}}, true, nil
+		}
+		return ecma48.Output{Raw: []rune{c}, Parsed: ecma48.CtrlChar{Char: upper}}, true, nil
+	case alt:
+		if shift {
+			r = unicode.ToUpper(r)
+			return ecma48.Output{Raw: []rune{27, r}, Parsed: ecma48.AltShiftChar{Char: r}}, true, nil
+		}
+		return ecma48.Output{Raw: []rune{27, r}, Parsed: ecma48.AltChar{Char: unicode.ToUpper(r)}}, true, nil
+	case shift:
+		r = unicode.ToUpper(r)
+		return ecma48.Output{Raw: []rune{r}, Parsed: ecma48.Char{Rune: r}}, true, nil
+	}
+	return ecma48.Output{Raw: []rune{r}, Parsed: parsed}, true, nil
+}
+
+// modifierParam is the parameter xterm adds to a key's escape sequence to say
+// which modifiers are held, or 1 for none
+func modifierParam(ctrl, alt, shift bool) int {
+	mod := 1
+	if shift {
+		mod += 1
+	}
+	if alt {
+		mod += 2
+	}
+	if ctrl {
+		mod += 4
+	}
+	return mod
+}
+
+// A functionKey is encoded as CSI param final, or as SS3 final if ss3 is set
+// and no modifiers are held
+type functionKey struct {
+	param int
+	final rune
+	ss3   bool
+}
+
+// functionKeys are the keys besides arrows that send escape sequences, encoded
+// as xterm does
+var functionKeys = map[string]functionKey{
+	"home": {1, 'H', false}, "end": {1, 'F', false},
+	"insert": {2, '~', false}, "delete": {3, '~', false},
+	"pageup": {5, '~', false}, "pagedown": {6, '~', false},
+	"f1": {1, 'P', true}, "f2": {1, 'Q', true}, "f3": {1, 'R', true}, "f4": {1, 'S', true},
+	"f5": {15, '~', false}, "f6": {17, '~', false}, "f7": {18, '~', false}, "f8": {19, '~', false},
+	"f9": {20, '~', false}, "f10": {21, '~', false}, "f11": {23, '~', false}, "f12": {24, '~', false},
+}
+
+// functionKeyName matches names of function keys that have no encoding, like
+// F13, so that they aren't typed out as text
+var functionKeyName = regexp.MustCompile(`^[Ff][0-9]+$`)
+
+func (k functionKey) encode(mod int) string {
+	switch {
+	case mod > 1:
+		return fmt.Sprintf("\x1b[%d;%d%c", k.param, mod, k.final)
+	case k.ss3:
+		return "\x1bO" + string(k.final)
+	case k.final == '~':
+		return fmt.Sprintf("\x1b[%d~", k.param)
+	default:
+		return "\x1b[" + string(k.final)
+	}
+}
+
+// literalInput turns text into input as if it were typed
+func literalInput(text string) []ecma48.Output {
+	out := []ecma48.Output{}
+	for _, r := range text {
+		out = append(out, ecma48.Output{Raw: []rune{r}, Parsed: ecma48.Char{Rune: r}})
+	}
+	return out
+}
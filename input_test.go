@@ -0,0 +1,93 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestDehumanify(t *testing.T) {
+	tests := []struct {
+		key   string
+		raw   string
+		isKey bool
+		err   bool
+	}{
+		{key: "Enter", raw: "\r", isKey: true},
+		{key: "return", raw: "\r", isKey: true},
+		{key: "Tab", raw: "\t", isKey: true},
+		{key: "Shift+Tab", raw: "\x1b[Z", isKey: true},
+		{key: "Escape", raw: "\x1b", isKey: true},
+		{key: "Backspace", raw: "\x7f", isKey: true},
+		{key: "Space", raw: " ", isKey: true},
+
+		{key: "Ctrl+C", raw: "\x03", isKey: true},
+		{key: "ctrl+a", raw: "\x01", isKey: true},
+		{key: "Ctrl+Shift+A", raw: "\x01", isKey: true},
+		{key: "Ctrl+Alt+C", raw: "\x1b\x03", isKey: true},
+		{key: "Alt+x", raw: "\x1bx", isKey: true},
+		{key: "Alt+Shift+x", raw: "\x1bX", isKey: true},
+		{key: "Shift+x", raw: "X", isKey: true},
+
+		{key: "Up", raw: "\x1b[A", isKey: true},
+		{key: "Left", raw: "\x1b[D", isKey: true},
+		{key: "Shift+Up", raw: "\x1b[1;2A", isKey: true},
+		{key: "Alt+Shift+Down", raw: "\x1b[1;4B", isKey: true},
+		{key: "Ctrl+Right", raw: "\x1b[1;5C", isKey: true},
+
+		{key: "Home", raw: "\x1b[H", isKey: true},
+		{key: "End", raw: "\x1b[F", isKey: true},
+		{key: "Ctrl+End", raw: "\x1b[1;5F", isKey: true},
+		{key: "Insert", raw: "\x1b[2~", isKey: true},
+		{key: "Delete", raw: "\x1b[3~", isKey: true},
+		{key: "PageUp", raw: "\x1b[5~", isKey: true},
+		{key: "Shift+PageDown", raw: "\x1b[6;2~", isKey: true},
+		{key: "F1", raw: "\x1bOP", isKey: true},
+		{key: "f4", raw: "\x1bOS", isKey: true},
+		{key: "Ctrl+F1", raw: "\x1b[1;5P", isKey: true},
+		{key: "F5", raw: "\x1b[15~", isKey: true},
+		{key: "F12", raw: "\x1b[24~", isKey: true},
+		{key: "Alt+F12", raw: "\x1b[24;3~", isKey: true},
+
+		{key: "hello"},
+		{key: "x"},
+		{key: "make test"},
+
+		{key: "Ctrl+1", isKey: true, err: true},
+		{key: "Ctrl+Enter", isKey: true, err: true},
+		{key: "Alt+foo", isKey: true, err: true},
+		{key: "F13", isKey: true, err: true},
+	}
+
+	for _, tt := range tests {
+		out, isKey, err := dehumanify(tt.key)
+		if isKey != tt.isKey {
+			t.Errorf("dehumanify(%q): isKey = %v, want %v", tt.key, isKey, tt.isKey)
+		}
+		if (err != nil) != tt.err {
+			t.Errorf("dehumanify(%q): err = %v, want error: %v", tt.key, err, tt.err)
+		}
+		if string(out.Raw) != tt.raw {
+			t.Errorf("dehumanify(%q) = %q, want %q", tt.key, string(out.Raw), tt.raw)
+		}
+	}
+}
+
+func TestParsePaneID(t *testing.T) {
+	tests := []struct {
+		in  string
+		id  int
+		err bool
+	}{
+		{in: "3", id: 3},
+		{in: "%12", id: 12},
+		{in: "", err: true},
+		{in: "%", err: true},
+		{in: "pane", err: true},
+	}
+
+	for _, tt := range tests {
+		id, err := parsePaneID(tt.in)
+		if (err != nil) != tt.err || id != tt.id {
+			t.Errorf("parsePaneID(%q) = %d, %v", tt.in, id, err)
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/url"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+
+	"github.com/aaronjanse/3mux/wm"
+)
+
+// readLayoutFile reads a layout file, making sure it is valid before a
+// session is created from it
+func readLayoutFile(layoutPath string) ([]byte, error) {
+	data, err := ioutil.ReadFile(layoutPath)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read layout: %s", err)
+	}
+	_, err = wm.ParseLayout(data)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid layout in %s: %s", layoutPath, err)
+	}
+	return data, nil
+}
+
+// loadSessionLayout returns the layout a session was created with, or nil if
+// it was created without one
+func loadSessionLayout(sessionInfo *SessionInfo) (*wm.Layout, error) {
+	data, err := ioutil.ReadFile(sessionInfo.layoutPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	// it's only needed once
+	os.Remove(sessionInfo.layoutPath)
+
+	return wm.ParseLayout(data)
+}
+
+// saveLayout implements `3mux save-layout`, writing a session's layout to a
+// file or, if no file is given, to stdout
+func saveLayout(sessionName string, outPath string) error {
+	sessionInfo, found, err := findSession(sessionName)
+	if err != nil {
+		return fmt.Errorf("Error while querying sessions: %s", err)
+	}
+	if !found {
+		return fmt.Errorf("Failed to find session with name: %s", sessionName)
+	}
+
+	reply, err := sendControl(sessionInfo, "get-layout", nil)
+	if err != nil {
+		return err
+	}
+	if !reply.OK {
+		return errors.New(reply.Error)
+	}
+
+	// round-trip through wm.Layout so fields keep their documented order
+	raw, err := json.Marshal(reply.Result)
+	if err != nil {
+		return err
+	}
+	var layout wm.Layout
+	err = json.Unmarshal(raw, &layout)
+	if err != nil {
+		return err
+	}
+	out, err := json.MarshalIndent(layout, "", "  ")
+	if err != nil {
+		return err
+	}
+	out = append(out, '\n')
+
+	if outPath == "" {
+		_, err = os.Stdout.Write(out)
+		return err
+	}
+	return ioutil.WriteFile(outPath, out, 0644)
+}
+
+// checkpointPath is where the checkpoint of the named session is kept. Unlike
+// the session's directory, it survives a reboot.
+func checkpointPath(sessionName string) (string, error) {
+	dir := os.Getenv("XDG_STATE_HOME")
+	if dir == "" {
+		usr, err := user.Current()
+		if err != nil {
+			return "", fmt.Errorf("Failed to get current user: %s", err)
+		}
+		dir = filepath.Join(usr.HomeDir, ".local", "state")
+	}
+	return filepath.Join(dir, "3mux", url.PathEscape(sessionName)+".json"), nil
+}
+
+// writeCheckpoint saves a session's layout so that it can be resurrected if
+// the server dies
+func writeCheckpoint(sessionInfo *SessionInfo, u *wm.Universe, history bool) {
+	data, err := json.Marshal(u.Layout(history))
+	if err != nil {
+		log.Println("Failed to encode checkpoint:", err)
+		return
+	}
+
+	outPath, err := checkpointPath(sessionInfo.name)
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(outPath), 0700)
+	}
+	if err == nil {
+		// write then rename so that a crash never leaves a partial checkpoint
+		tmpPath := outPath + ".tmp"
+		err = ioutil.WriteFile(tmpPath, data, 0600)
+		if err == nil {
+			err = os.Rename(tmpPath, outPath)
+		}
+	}
+	if err != nil {
+		log.Println("Failed to write checkpoint:", err)
+	}
+}
+
+// removeCheckpoint deletes the checkpoint of a session that ended on purpose,
+// so that it can't be resurrected
+func removeCheckpoint(sessionName string) error {
+	checkpoint, err := checkpointPath(sessionName)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(checkpoint)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// resurrectLayout reads the last checkpoint of a session whose server has
+// died, then removes what's left of the session so it can be recreated. After
+// a reboot, nothing but the checkpoint is left.
+func resurrectLayout(sessionName string) ([]byte, error) {
+	sessionInfo, found, err := findSession(sessionName)
+	if err != nil {
+		return nil, fmt.Errorf("Error while querying sessions: %s", err)
+	}
+	if found {
+		conn, err := net.Dial("unix", sessionInfo.controlPath)
+		if err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("Session is still running. To attach to it, run `3mux attach %s`", sessionName)
+		}
+	}
+
+	checkpoint, err := checkpointPath(sessionName)
+	if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadFile(checkpoint)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("Failed to find a checkpoint of session: %s", sessionName)
+	} else if err != nil {
+		return nil, fmt.Errorf("Failed to read checkpoint: %s", err)
+	}
+	layout, err := wm.ParseLayout(data)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid checkpoint: %s", err)
+	}
+
+	config, err := loadOrGenerateConfig()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to load or generate config: %s", err)
+	}
+	allowed := map[string]bool{}
+	for _, name := range config.generalSettings.ResurrectCommands {
+		allowed[name] = true
+	}
+	for i := range layout.Workspaces {
+		filterCommands(&layout.Workspaces[i], allowed)
+	}
+	for i := range layout.Scratchpad {
+		filterCommands(&layout.Scratchpad[i].LayoutNode, allowed)
+	}
+
+	data, err = json.Marshal(layout)
+	if err != nil {
+		return nil, err
+	}
+
+	if found {
+		err = os.RemoveAll(sessionInfo.path)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to remove metadata directory `%s`: %s", sessionInfo.path, err)
+		}
+	}
+	return data, nil
+}
+
+// filterCommands clears each pane's command unless the program it runs is
+// allowed
+func filterCommands(n *wm.LayoutNode, allowed map[string]bool) {
+	if n.Pane != nil {
+		fields := strings.Fields(n.Pane.Command)
+		if len(fields) == 0 || !allowed[filepath.Base(strings.Trim(fields[0], "'"))] {
+			n.Pane.Command = ""
+		}
+	}
+	for i := range n.Children {
+		filterCommands(&n.Children[i], allowed)
+	}
+	for i := range n.Floating {
+		filterCommands(&n.Floating[i].LayoutNode, allowed)
+	}
+}
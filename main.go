@@ -64,7 +64,7 @@ func main() {
 				}
 			}
 			if choice == "y" {
-				detach(parentSessionID)
+				detach(parentSessionID, false)
 				os.Exit(0)
 			}
 			os.Exit(1)
@@ -126,6 +126,9 @@ func main() {
 		err = serve(sessionInfo)
 		if err == nil {
 			log.Println("Exiting cleanly...")
+			if err := removeCheckpoint(sessionName); err != nil {
+				log.Println("Failed to remove checkpoint:", err)
+			}
 			err := os.RemoveAll(sessionInfo.path)
 			if err != nil {
 				log.Printf("Failed to remove metadata directory `%s`: %s\n", sessionInfo.path, err)
@@ -141,8 +144,18 @@ func main() {
 		if parentSessionID != "" {
 			refuseNesting()
 		}
+		var layout []byte
+		if len(os.Args) == 5 && os.Args[3] == "--layout" {
+			var err error
+			layout, err = readLayoutFile(os.Args[4])
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			os.Args = os.Args[:3]
+		}
 		if len(os.Args) != 3 {
-			fmt.Println("Usage: 3mux new <name>")
+			fmt.Println("Usage: 3mux new <name> [--layout <file>]")
 			os.Exit(1)
 		}
 		sessionName := os.Args[2]
@@ -157,52 +170,44 @@ func main() {
 			os.Exit(1)
 		}
 
-		initializeSession(sessionName)
-
-		daemonContext := &daemon.Context{
-			Args: []string{
-				os.Args[0],
-				"new-server-internal-only",
-				sessionName,
-			},
+		startSession(sessionName, layout)
+		attachSession(sessionName, false)
+	case "attach":
+		if parentSessionID != "" {
+			refuseNesting()
 		}
-		child, err := daemonContext.Reborn()
-		if err != nil {
-			fmt.Println("Error occured while spawning session daemon:", err)
-			os.Exit(1)
+		readOnly := false
+		args := []string{}
+		for _, arg := range os.Args[2:] {
+			if arg == "--read-only" {
+				readOnly = true
+			} else {
+				args = append(args, arg)
+			}
 		}
-		if child == nil {
-			fmt.Println("Encountered 'unreachable' state with server-client mismatch. Args:", os.Args)
+		if len(args) != 1 {
+			fmt.Println("Usage: 3mux attach [--read-only] <name>")
 			os.Exit(1)
 		}
-
-		os.Args[1] = "attach"
-		fallthrough
-	case "attach":
+		attachSession(args[0], readOnly)
+	case "resurrect":
 		if parentSessionID != "" {
 			refuseNesting()
 		}
 		if len(os.Args) != 3 {
-			fmt.Println("Usage: 3mux attach <name>")
+			fmt.Println("Usage: 3mux resurrect <name>")
 			os.Exit(1)
 		}
 		sessionName := os.Args[2]
-		sessionInfo, found, err := findSession(sessionName)
-		if err != nil {
-			fmt.Println("Error while querying sessions:", err)
-			os.Exit(1)
-		}
-		if !found {
-			fmt.Println("Failed to find session with name:", sessionName)
-			os.Exit(1)
-		}
-		err = attach(sessionInfo)
+
+		layout, err := resurrectLayout(sessionName)
 		if err != nil {
 			fmt.Println(err)
-			fmt.Println("See server-side logs at", path.Join(sessionInfo.path, "logs-server.txt"))
-			fmt.Printf("To manually kill this session, run `3mux kill %s`\n", sessionName)
 			os.Exit(1)
 		}
+
+		startSession(sessionName, layout)
+		attachSession(sessionName, false)
 	case "kill":
 		if len(os.Args) != 3 {
 			fmt.Println("Usage: 3mux kill <name>")
@@ -234,6 +239,9 @@ func main() {
 			fmt.Printf("Please also report this to %s\n", BUG_REPORT_URL)
 			os.Exit(1)
 		}
+		if err := removeCheckpoint(sessionName); err != nil {
+			fmt.Println("Failed to remove checkpoint:", err)
+		}
 		fmt.Println("Session successfully killed.")
 	case "ls", "ps":
 		children, err := ioutil.ReadDir(threemuxDir)
@@ -246,14 +254,112 @@ func main() {
 			thisName, _ := ioutil.ReadFile(namePath)
 			fmt.Printf("- %s\n", string(thisName))
 		}
+	case "msg":
+		if len(os.Args) < 4 {
+			fmt.Println("Usage: 3mux msg <name> <command> [args...]")
+			os.Exit(1)
+		}
+		err := runMsg(os.Args[2], os.Args[3], os.Args[4:])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	case "send-keys":
+		if len(os.Args) < 5 {
+			fmt.Println("Usage: 3mux send-keys <name> [-l] <pane> <key>...")
+			os.Exit(1)
+		}
+		err := runMsg(os.Args[2], "send-keys", os.Args[3:])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	case "capture-pane":
+		if len(os.Args) < 4 {
+			fmt.Println("Usage: 3mux capture-pane <name> [-e] [-S start] [-E end] <pane>")
+			os.Exit(1)
+		}
+		err := runMsg(os.Args[2], "capture-pane", os.Args[3:])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	case "save-layout":
+		if len(os.Args) != 3 && len(os.Args) != 4 {
+			fmt.Println("Usage: 3mux save-layout <name> [file]")
+			os.Exit(1)
+		}
+		outPath := ""
+		if len(os.Args) == 4 {
+			outPath = os.Args[3]
+		}
+		err := saveLayout(os.Args[2], outPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
 	case "detach":
 		if parentSessionID == "" {
 			fmt.Println("Must be within session to detach")
 			os.Exit(1)
 		}
-		detach(parentSessionID)
+		all := len(os.Args) == 3 && os.Args[2] == "--all"
+		if len(os.Args) > 2 && !all {
+			fmt.Println("Usage: 3mux detach [--all]")
+			os.Exit(1)
+		}
+		detach(parentSessionID, all)
 	default:
-		fmt.Println(helpText)
+		fmt.Print(helpText)
+		os.Exit(1)
+	}
+}
+
+// startSession creates a session and spawns a server for it. If layout is
+// non-nil, the server builds the session from it.
+func startSession(sessionName string, layout []byte) {
+	sessionInfo := initializeSession(sessionName)
+	if layout != nil {
+		err := ioutil.WriteFile(sessionInfo.layoutPath, layout, 0600)
+		if err != nil {
+			fmt.Println("Failed to record session layout:", err)
+			os.Exit(1)
+		}
+	}
+
+	daemonContext := &daemon.Context{
+		Args: []string{
+			os.Args[0],
+			"new-server-internal-only",
+			sessionName,
+		},
+	}
+	child, err := daemonContext.Reborn()
+	if err != nil {
+		fmt.Println("Error occured while spawning session daemon:", err)
+		os.Exit(1)
+	}
+	if child == nil {
+		fmt.Println("Encountered 'unreachable' state with server-client mismatch. Args:", os.Args)
+		os.Exit(1)
+	}
+}
+
+func attachSession(sessionName string, readOnly bool) {
+	sessionInfo, found, err := findSession(sessionName)
+	if err != nil {
+		fmt.Println("Error while querying sessions:", err)
+		os.Exit(1)
+	}
+	if !found {
+		fmt.Println("Failed to find session with name:", sessionName)
+		os.Exit(1)
+	}
+	err = attach(sessionInfo, readOnly)
+	if err != nil {
+		fmt.Println(err)
+		fmt.Println("See server-side logs at", path.Join(sessionInfo.path, "logs-server.txt"))
+		fmt.Printf("To manually kill this session, run `3mux kill %s`\n", sessionName)
 		os.Exit(1)
 	}
 }
@@ -263,15 +369,29 @@ type SessionInfo struct {
 	uuid           string
 	path           string
 	fdPath         string
-	killClientPath string
 	killServerPath string
 	detachPath     string
 	resizePath     string
+	controlPath    string
 	logsPath       string
+
+	// layoutPath holds the layout a new session should start with
+	layoutPath string
+}
+
+// detach detaches the client that ran `3mux detach`, or every client in the
+// session if all is set
+func detach(parentSessionID string, all bool) {
+	clientID := detachActive
+	if all {
+		clientID = ""
+	}
+	requestDetach(elaborateSessionInfo("", parentSessionID), clientID)
 }
 
-func detach(parentSessionID string) {
-	net.Dial("unix", elaborateSessionInfo("", parentSessionID).detachPath)
+// clientKillPath is the socket the server dials to tell a client to exit
+func (s *SessionInfo) clientKillPath(clientID string) string {
+	return path.Join(s.path, fmt.Sprintf("kill-client-%s.sock", clientID))
 }
 
 func elaborateSessionInfo(name string, uuid string) *SessionInfo {
@@ -281,11 +401,12 @@ func elaborateSessionInfo(name string, uuid string) *SessionInfo {
 		uuid:           uuid,
 		path:           dirPath,
 		fdPath:         path.Join(dirPath, "fd.sock"),
-		killClientPath: path.Join(dirPath, "kill-client.sock"),
 		killServerPath: path.Join(dirPath, "kill-server.sock"),
 		detachPath:     path.Join(dirPath, "detach-server.sock"),
 		resizePath:     path.Join(dirPath, "resize.sock"),
+		controlPath:    path.Join(dirPath, "control.sock"),
 		logsPath:       path.Join(dirPath, "logs-server.txt"),
+		layoutPath:     path.Join(dirPath, "layout.json"),
 	}
 }
 
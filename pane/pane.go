@@ -3,9 +3,16 @@ package pane
 import (
 	"bufio"
 	"fmt"
+	"io"
+	"log"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"runtime/debug"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/aaronjanse/3mux/ecma48"
 	"github.com/aaronjanse/3mux/vterm"
@@ -13,17 +20,44 @@ import (
 	"github.com/aaronjanse/pty"
 )
 
+// lastID is the ID of the most recently created pane
+var lastID int64
+
 // A Pane is a tiling unit representing a terminal
 type Pane struct {
+	id   int
 	born bool
 
 	ptmx  *os.File
 	cmd   *exec.Cmd
 	vterm *vterm.VTerm
 
+	// history is shown before the shell's output
+	history string
+
 	selected   bool
 	renderRect wm.Rect
 	renderer   ecma48.Renderer
+	theme      wm.Theme
+
+	// hidden is 1 while the pane's workspace is hidden. It is accessed
+	// atomically since the vterm checks it while drawing.
+	hidden uint32
+	// covered holds the []wm.Rect taken up by floating panes above this one
+	covered atomic.Value
+	// fgName holds the name of the program in the foreground, as a string.
+	// Finding it takes a syscall and a read from /proc, so it is refreshed
+	// every foregroundInterval rather than whenever the title is drawn.
+	fgName atomic.Value
+
+	// resizeMu guards the pty's size, which is set at most once every
+	// resizeInterval so that dragging a border doesn't flood the shell with
+	// SIGWINCH. resizeTimer is set while a resize is waiting its turn. Dead is
+	// also guarded by resizeMu, since the pty is closed once the pane dies.
+	resizeMu         sync.Mutex
+	resizeTimer      *time.Timer
+	lastResize       time.Time
+	resizeW, resizeH int
 
 	searchMode            bool
 	searchText            string
@@ -37,7 +71,7 @@ type Pane struct {
 	OnDeath func(error)
 }
 
-func NewPane(renderer ecma48.Renderer, realShell bool, sessionID string) wm.Node {
+func NewPane(renderer ecma48.Renderer, realShell bool, sessionID string, theme wm.Theme, spec wm.PaneSpec) wm.Node {
 	shellPath, err := getShellPath()
 	if err != nil {
 		panic(err)
@@ -48,12 +82,21 @@ func NewPane(renderer ecma48.Renderer, realShell bool, sessionID string) wm.Node
 	}
 
 	cmd := exec.Command(shellPath)
+	if spec.Exec && realShell {
+		cmd = exec.Command(shellPath, "-c", spec.Command)
+	}
 	cmd.Env = append(os.Environ(), "TERM=xterm-256color") // FIXME we should decide whether we want 256color in $TERM
 	cmd.Env = append(cmd.Env, fmt.Sprintf("THREEMUX=%s", sessionID))
+	if info, err := os.Stat(spec.Cwd); err == nil && info.IsDir() {
+		cmd.Dir = spec.Cwd
+	}
 	t := &Pane{
+		id:       int(atomic.AddInt64(&lastID, 1)),
 		born:     false,
 		renderer: renderer,
+		theme:    theme,
 		cmd:      cmd,
+		history:  strings.ReplaceAll(spec.History, "\n", "\r\n"),
 	}
 
 	ptmx, err := pty.Start(t.cmd)
@@ -61,14 +104,22 @@ func NewPane(renderer ecma48.Renderer, realShell bool, sessionID string) wm.Node
 		panic(err)
 	}
 	t.ptmx = ptmx
+	t.refreshForeground()
+
+	if spec.Command != "" && !spec.Exec {
+		// the shell reads this once it's ready, just as if it were typed
+		t.ptmx.Write([]byte(spec.Command + "\r"))
+	}
 
 	parentSetCursor := func(x, y int) {
-		if t.selected {
-			renderer.SetCursor(x+t.renderRect.X, y+t.renderRect.Y)
+		x += t.renderRect.X
+		y += t.renderRect.Y
+		if t.selected && !t.isHidden() && !t.isCovered(x, y) {
+			renderer.SetCursor(x, y)
 		}
 	}
 
-	t.vterm = vterm.NewVTerm(renderer, parentSetCursor)
+	t.vterm = vterm.NewVTerm(paneRenderer{t}, parentSetCursor)
 
 	return t
 }
@@ -77,19 +128,23 @@ func (t *Pane) SetRenderRect(fullscreen bool, x, y, w, h int) {
 	t.renderRect = wm.Rect{X: x, Y: y, W: w, H: h}
 
 	if !t.born {
+		done := make(chan struct{})
+		go t.watchForeground(done)
 		go func() {
+			defer close(done)
 			defer func() {
 				if r := recover(); r != nil {
-					t.Dead = true
+					t.markDead()
 					t.OnDeath(fmt.Errorf("%s\n%s",
 						r.(error), debug.Stack(),
 					))
 				}
 			}()
 
-			t.vterm.ProcessStdout(bufio.NewReader(t.ptmx))
+			stdout := io.MultiReader(strings.NewReader(t.history), t.ptmx)
+			t.vterm.ProcessStdout(bufio.NewReader(stdout))
 
-			t.Dead = true
+			t.markDead()
 			t.OnDeath(nil)
 		}()
 		t.born = true
@@ -103,25 +158,205 @@ func (t *Pane) SetRenderRect(fullscreen bool, x, y, w, h int) {
 	t.resizeShell(w, h)
 }
 
+// resizeInterval is the least time between two resizes of a pane's pty
+const resizeInterval = 50 * time.Millisecond
+
 func (t *Pane) resizeShell(w, h int) {
+	t.resizeMu.Lock()
+	defer t.resizeMu.Unlock()
+
+	if t.Dead {
+		return
+	}
+	t.resizeW, t.resizeH = w, h
+	if t.resizeTimer != nil {
+		// the waiting resize will use the newest size
+		return
+	}
+
+	wait := resizeInterval - time.Since(t.lastResize)
+	if wait <= 0 {
+		if err := t.applyResize(); err != nil {
+			panic(err)
+		}
+		return
+	}
+	t.resizeTimer = time.AfterFunc(wait, func() {
+		t.resizeMu.Lock()
+		defer t.resizeMu.Unlock()
+
+		t.resizeTimer = nil
+		if t.Dead {
+			return
+		}
+		// nothing would recover a panic on the timer's goroutine
+		if err := t.applyResize(); err != nil {
+			log.Println("Failed to resize pane:", err)
+		}
+	})
+}
+
+// applyResize sets the pty's size; resizeMu must be held
+func (t *Pane) applyResize() error {
+	w, h := t.resizeW, t.resizeH
 	err := pty.Setsize(t.ptmx, &pty.Winsize{
 		Rows: uint16(h), Cols: uint16(w),
 		X: 16 * uint16(w), Y: 16 * uint16(h),
 	})
 	if err != nil {
-		panic(err)
+		return err
+	}
+	t.lastResize = time.Now()
+	return nil
+}
+
+// markDead records that the pane has died, after which its pty is never
+// resized
+func (t *Pane) markDead() {
+	t.resizeMu.Lock()
+	defer t.resizeMu.Unlock()
+
+	if t.resizeTimer != nil {
+		t.resizeTimer.Stop()
+		t.resizeTimer = nil
+	}
+	t.Dead = true
+}
+
+// ScrollDown shows older lines of scrollback. Programs on the alt screen,
+// which has no scrollback, are sent arrow keys instead.
+func (t *Pane) ScrollDown(lines int) {
+	if t.vterm.InAltScreen() {
+		t.sendWheelKeys(ecma48.Up, lines)
+		return
+	}
+	t.vterm.ScrollbackDown(lines)
+}
+
+// ScrollUp shows newer lines of scrollback, or sends arrow keys like
+// ScrollDown
+func (t *Pane) ScrollUp(lines int) {
+	if t.vterm.InAltScreen() {
+		t.sendWheelKeys(ecma48.Down, lines)
+		return
+	}
+	t.vterm.ScrollbackUp(lines)
+}
+
+func (t *Pane) sendWheelKeys(d ecma48.Direction, lines int) {
+	out := t.vterm.WheelKeys(d, lines)
+	if out == nil {
+		return
+	}
+	// the pane may have just died, which its output goroutine reports, so
+	// losing a turn of the wheel is all that a failed write costs
+	_, err := t.ptmx.Write(out)
+	if err != nil {
+		log.Println("Failed to send scroll wheel to pane:", err)
+	}
+}
+
+// Capture returns the text of the given lines of scrollback and screen. See
+// vterm.VTerm.Capture for how lines are numbered.
+func (t *Pane) Capture(start, end int, styled bool) string {
+	return t.vterm.Capture(start, end, styled)
+}
+
+// LineCount returns how many lines of scrollback and screen the pane has
+func (t *Pane) LineCount() (scrollback, screen int) {
+	return t.vterm.LineCount()
+}
+
+// historyLines is the most scrollback that Spec will include
+const historyLines = 2000
+
+// Spec describes the working directory of the pane's shell and the command
+// running in the foreground, if any
+func (t *Pane) Spec(history bool) wm.PaneSpec {
+	spec := wm.PaneSpec{}
+	if history {
+		if text := t.vterm.History(historyLines); text != "" {
+			spec.History = text + "\n"
+		}
+	}
+
+	if t.cmd.Process == nil {
+		return spec
+	}
+	shellPid := t.cmd.Process.Pid
+
+	cwd, err := os.Readlink(fmt.Sprintf("/proc/%d/cwd", shellPid))
+	if err == nil {
+		spec.Cwd = cwd
 	}
+
+	fg, err := foregroundProcess(t.ptmx)
+	if err == nil && fg != shellPid {
+		argv, err := processArgs(fg)
+		if err == nil {
+			spec.Command = shellJoin(argv)
+		}
+	}
+
+	return spec
 }
 
-func (t *Pane) ScrollDown() {
-	t.vterm.ScrollbackDown()
+// Title is the title set by the program running in the pane or, if it hasn't
+// set one, the name of the program running in the foreground
+func (t *Pane) Title() string {
+	if title := t.vterm.Title(); title != "" {
+		return title
+	}
+	name, _ := t.fgName.Load().(string)
+	return name
 }
 
-func (t *Pane) ScrollUp() {
-	t.vterm.ScrollbackUp()
+// foregroundInterval is how often a pane checks which program is in the
+// foreground
+const foregroundInterval = time.Second
+
+// watchForeground refreshes the name of the program in the foreground until
+// done is closed, reporting changes to the title
+func (t *Pane) watchForeground(done <-chan struct{}) {
+	ticker := time.NewTicker(foregroundInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+		if t.refreshForeground() && t.vterm.Title() == "" && t.vterm.OnTitleChange != nil {
+			t.vterm.OnTitleChange()
+		}
+	}
+}
+
+// refreshForeground looks up the name of the program in the foreground,
+// falling back to the pane's own command, and reports whether it changed
+func (t *Pane) refreshForeground() bool {
+	name := filepath.Base(t.cmd.Path)
+	fg, err := foregroundProcess(t.ptmx)
+	if err == nil {
+		fgName, err := processName(fg)
+		if err == nil && fgName != "" {
+			name = fgName
+		}
+	}
+	old, _ := t.fgName.Load().(string)
+	t.fgName.Store(name)
+	return name != old
+}
+
+func (t *Pane) ID() int {
+	return t.id
 }
 
 func (t *Pane) IsDead() bool {
+	t.resizeMu.Lock()
+	defer t.resizeMu.Unlock()
+
 	return t.Dead
 }
 
@@ -129,8 +364,17 @@ func (t *Pane) SetDeathHandler(onDeath func(error)) {
 	t.OnDeath = onDeath
 }
 
+// SetTitleHandler sets a function to be called when the pane's title changes
+func (t *Pane) SetTitleHandler(onChange func()) {
+	t.vterm.OnTitleChange = onChange
+}
+
 func (t *Pane) UpdateSelection(selected bool) {
+	changed := t.selected != selected
 	t.selected = selected
+	if changed && t.theme.DimInactive {
+		t.vterm.RedrawWindow()
+	}
 	if selected {
 		t.vterm.RefreshCursor()
 	}
@@ -149,14 +393,32 @@ func (t *Pane) HandleStdin(in ecma48.Output) {
 	}
 }
 
+// SendMouse passes a mouse event, in the pane's own coordinates, to the
+// program running in the pane, returning false if it didn't ask for it
+func (t *Pane) SendMouse(ev ecma48.Parsed) bool {
+	if t.searchMode {
+		return false
+	}
+	out := t.vterm.EncodeMouse(ev)
+	if out == nil {
+		return false
+	}
+	_, err := t.ptmx.Write(out)
+	if err != nil {
+		panic(err)
+	}
+	return true
+}
+
 func (t *Pane) Kill() {
+	// a pending resize must not reach the pty once it is closed
+	t.markDead()
+
 	t.vterm.Kill()
 	// FIXME: handle error
 	t.ptmx.Close()
 	// FIXME: handle error
 	t.cmd.Process.Kill()
-
-	t.Dead = true
 }
 
 func (t *Pane) SetPaused(pause bool) {
@@ -164,8 +426,58 @@ func (t *Pane) SetPaused(pause bool) {
 	t.vterm.IsPaused = pause
 }
 
+// SetHidden stops the pane from drawing while it keeps running. Once it is
+// shown again, SetRenderRect redraws it.
+func (t *Pane) SetHidden(hidden bool) {
+	var v uint32
+	if hidden {
+		v = 1
+	}
+	atomic.StoreUint32(&t.hidden, v)
+}
+
+func (t *Pane) isHidden() bool {
+	return atomic.LoadUint32(&t.hidden) == 1
+}
+
+// SetCovered stops the pane from drawing within the given rects
+func (t *Pane) SetCovered(rects []wm.Rect) {
+	t.covered.Store(rects)
+}
+
+func (t *Pane) isCovered(x, y int) bool {
+	rects, _ := t.covered.Load().([]wm.Rect)
+	for _, r := range rects {
+		if r.X <= x && x < r.X+r.W && r.Y <= y && y < r.Y+r.H {
+			return true
+		}
+	}
+	return false
+}
+
+// paneRenderer passes along what the pane's vterm draws unless the pane is
+// hidden or that part of it is covered
+type paneRenderer struct {
+	t *Pane
+}
+
+func (r paneRenderer) HandleCh(ch ecma48.PositionedChar) {
+	if !r.t.isHidden() && !r.t.isCovered(ch.Cursor.X, ch.Cursor.Y) {
+		if r.t.theme.DimInactive && !r.t.selected {
+			ch.Cursor.Style.Faint = true
+		}
+		r.t.renderer.HandleCh(ch)
+	}
+}
+
+func (r paneRenderer) SetCursor(x, y int) {
+	if !r.t.isHidden() && !r.t.isCovered(x, y) {
+		r.t.renderer.SetCursor(x, y)
+	}
+}
+
 func (t *Pane) Serialize() string {
-	out := fmt.Sprintf("Term[%d,%d %dx%d]", t.renderRect.X, t.renderRect.Y, t.renderRect.W, t.renderRect.H)
+	out := fmt.Sprintf("Term#%d[%d,%d %dx%d]", t.id, t.renderRect.X, t.renderRect.Y, t.renderRect.W, t.renderRect.H)
 	if t.selected {
 		return out + "*"
 	}
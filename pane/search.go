@@ -114,18 +114,9 @@ func (t *Pane) doSearch() {
 			t.renderer.HandleCh(ecma48.PositionedChar{
 				Rune: fullBuffer[theY][i].Rune,
 				Cursor: ecma48.Cursor{
-					X: t.renderRect.X + i,
-					Y: t.renderRect.Y + t.renderRect.H - match.y1,
-					Style: ecma48.Style{
-						Bg: ecma48.Color{
-							ColorMode: ecma48.ColorBit3Bright,
-							Code:      2,
-						},
-						Fg: ecma48.Color{
-							ColorMode: ecma48.ColorBit3Normal,
-							Code:      0,
-						},
-					},
+					X:     t.renderRect.X + i,
+					Y:     t.renderRect.Y + t.renderRect.H - match.y1,
+					Style: t.theme.SearchHighlight,
 				},
 			})
 		}
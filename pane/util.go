@@ -2,9 +2,14 @@ package pane
 
 import (
 	"bufio"
+	"bytes"
 	"errors"
+	"fmt"
+	"io/ioutil"
 	"os"
 	"strings"
+	"syscall"
+	"unsafe"
 )
 
 func getShellPath() (string, error) {
@@ -33,3 +38,53 @@ func getShellPath() (string, error) {
 	}
 	return "", errors.New("Could not find shell to use")
 }
+
+// foregroundProcess returns the ID of the foreground process group of a
+// terminal, which is also the PID of that group's leader
+func foregroundProcess(tty *os.File) (int, error) {
+	var pgrp int32
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, tty.Fd(),
+		uintptr(syscall.TIOCGPGRP), uintptr(unsafe.Pointer(&pgrp)))
+	if errno != 0 {
+		return 0, errno
+	}
+	return int(pgrp), nil
+}
+
+// processArgs returns the command line of a process
+func processArgs(pid int) ([]string, error) {
+	raw, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/cmdline", pid))
+	if err != nil {
+		return nil, err
+	}
+	raw = bytes.TrimRight(raw, "\x00")
+	if len(raw) == 0 {
+		return nil, errors.New("empty command line")
+	}
+	return strings.Split(string(raw), "\x00"), nil
+}
+
+// processName returns the name of a process's executable
+func processName(pid int) (string, error) {
+	raw, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/comm", pid))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(raw)), nil
+}
+
+// shellJoin quotes arguments so that a POSIX shell would split them back up
+// the same way
+func shellJoin(argv []string) string {
+	quoted := make([]string, len(argv))
+	for i, arg := range argv {
+		if arg != "" && strings.Trim(arg, shellSafeChars) == "" {
+			quoted[i] = arg
+		} else {
+			quoted[i] = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+		}
+	}
+	return strings.Join(quoted, " ")
+}
+
+const shellSafeChars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789@%+=:,./-_"
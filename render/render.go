@@ -23,46 +23,79 @@ type Renderer struct {
 
 	writingMutex  *sync.Mutex
 	pendingScreen [][]ecma48.StyledChar
-	currentScreen [][]ecma48.StyledChar
+
+	// clients are the host terminals we draw to, keyed by client ID
+	clients map[string]*client
 
 	highlights [][]bool
 
-	drawingCursor ecma48.Cursor
 	restingCursor ecma48.Cursor
 
 	Pause  chan bool
 	Resume chan bool
 
 	DemoText string
+}
+
+// A client is a host terminal attached to the renderer. Each client keeps
+// track of what it is currently displaying so that it only receives diffs.
+type client struct {
+	fd            int
+	currentScreen [][]ecma48.StyledChar
+	drawingCursor ecma48.Cursor
 
-	OutFd int
+	// label is drawn over the right end of the status bar for this client only
+	label string
 }
 
 // NewRenderer returns an initialized Renderer
-func NewRenderer(out int) *Renderer {
+func NewRenderer() *Renderer {
 	return &Renderer{
 		renderMode:    0,
 		charQueue:     make(chan ecma48.PositionedChar, queueBufferSize),
 		writingMutex:  &sync.Mutex{},
-		currentScreen: [][]ecma48.StyledChar{},
 		pendingScreen: [][]ecma48.StyledChar{},
+		clients:       map[string]*client{},
 		Pause:         make(chan bool),
 		Resume:        make(chan bool),
-		OutFd:         out,
 	}
 }
 
-func (r *Renderer) Write(data []byte) {
-	if r.OutFd != -1 {
-		// log.Printf("%+q\n", data)
-		syscall.Write(r.OutFd, data)
+// cellFor returns what the client should display at the given coordinates,
+// taking the client's label into account
+func (r *Renderer) cellFor(c *client, x, y int, ch ecma48.StyledChar) ecma48.StyledChar {
+	if y != r.h || c.label == "" {
+		return ch
+	}
+	start := r.w - len(c.label)
+	if x < start || start < 0 {
+		return ch
+	}
+	return ecma48.StyledChar{
+		Rune: rune(c.label[x-start]),
+		Style: ecma48.Style{
+			Bold: true,
+			Fg:   ecma48.Color{ColorMode: ecma48.ColorBit3Normal, Code: 7},
+			Bg:   ecma48.Color{ColorMode: ecma48.ColorBit3Normal, Code: 1},
+		},
 	}
 }
 
-// Resize changes the size of the framebuffers to match the host terminal size
+func (c *client) Write(data []byte) {
+	// log.Printf("%+q\n", data)
+	syscall.Write(c.fd, data)
+}
+
+// Resize changes the size of the framebuffers to match the host terminal size.
+// Render must already be running.
 func (r *Renderer) Resize(w, h int) {
+	r.Pause <- true
+	defer func() { r.Resume <- true }()
+
 	r.pendingScreen = expandBuffer(r.pendingScreen, w, h)
-	r.currentScreen = expandBuffer(r.currentScreen, w, h)
+	for _, c := range r.clients {
+		c.currentScreen = expandBuffer(c.currentScreen, w, h)
+	}
 
 	r.w = w
 	r.h = h
@@ -141,31 +174,43 @@ func (r *Renderer) RenderViaQueue() {
 					PrevWide: posCh.PrevWide,
 					Style:    posCh.Cursor.Style,
 				}
-				if r.currentScreen[posCh.Y][posCh.X] == pendingCh {
-					continue
+
+				// keep the pending screen up to date so that newly attached
+				// clients can be drawn from scratch
+				r.writingMutex.Lock()
+				r.pendingScreen[posCh.Y][posCh.X] = pendingCh
+				r.writingMutex.Unlock()
+
+				for _, c := range r.clients {
+					clientCh := r.cellFor(c, posCh.X, posCh.Y, pendingCh)
+					if c.currentScreen[posCh.Y][posCh.X] == clientCh {
+						continue
+					}
+					c.currentScreen[posCh.Y][posCh.X] = clientCh
+
+					newCursor := ecma48.Cursor{X: posCh.X, Y: posCh.Y, Style: clientCh.Style}
+					delta := deltaMarkup(c.drawingCursor, newCursor) + string(clientCh.Rune)
+					c.Write([]byte(delta))
+					c.drawingCursor = newCursor
+					if clientCh.IsWide {
+						c.drawingCursor.X += 2
+					} else {
+						c.drawingCursor.X++
+					}
 				}
-				r.currentScreen[posCh.Y][posCh.X] = pendingCh
-
-				delta := deltaMarkup(r.drawingCursor, posCh.Cursor) + string(posCh.Rune)
-				r.Write([]byte(delta))
-				r.drawingCursor = posCh.Cursor
-				if posCh.IsWide {
-					r.drawingCursor.X += 2
-				} else {
-					r.drawingCursor.X++
+			}
+			for _, c := range r.clients {
+				if c.drawingCursor != r.restingCursor {
+					delta := deltaMarkup(c.drawingCursor, r.restingCursor)
+					c.Write([]byte(delta))
+					c.drawingCursor = r.restingCursor
 				}
 			}
-			delta := deltaMarkup(r.drawingCursor, r.restingCursor)
-			r.Write([]byte(delta))
-			r.drawingCursor = r.restingCursor
 
 			// log.Println("# Queue length:", len(r.charQueue))
 			if len(r.charQueue) > (queueBufferSize*2)/3 {
 				r.writingMutex.Lock()
 				atomic.StoreUint32(&r.renderMode, 1)
-				for y := 0; y <= r.h; y++ {
-					copy(r.pendingScreen[y], r.currentScreen[y])
-				}
 				for posCh := range r.charQueue {
 					r.pendingScreen[posCh.Y][posCh.X] = ecma48.StyledChar{
 						Rune:     posCh.Rune,
@@ -198,10 +243,12 @@ func (r *Renderer) RenderViaFrames() {
 
 		emptyFrame := r.RenderSingleFrame()
 
-		if r.drawingCursor != r.restingCursor {
-			delta := deltaMarkup(r.drawingCursor, r.restingCursor)
-			r.Write([]byte(delta))
-			r.drawingCursor = r.restingCursor
+		for _, c := range r.clients {
+			if c.drawingCursor != r.restingCursor {
+				delta := deltaMarkup(c.drawingCursor, r.restingCursor)
+				c.Write([]byte(delta))
+				c.drawingCursor = r.restingCursor
+			}
 		}
 
 		if numEmptyFrames >= numEmptyFramesTillExit {
@@ -226,7 +273,19 @@ func (r *Renderer) RenderViaFrames() {
 	}
 }
 
+// RenderSingleFrame writes the difference between the pending screen and
+// what each client is displaying. It returns true if no client needed updates.
 func (r *Renderer) RenderSingleFrame() bool {
+	emptyFrame := true
+	for _, c := range r.clients {
+		if !r.renderClientFrame(c) {
+			emptyFrame = false
+		}
+	}
+	return emptyFrame
+}
+
+func (r *Renderer) renderClientFrame(c *client) bool {
 	emptyFrame := true
 	for {
 		fullyWritten := true
@@ -241,17 +300,17 @@ func (r *Renderer) RenderSingleFrame() bool {
 					break outer
 				}
 				r.writingMutex.Lock()
-				current := r.currentScreen[y][x]
-				pending := r.pendingScreen[y][x]
+				current := c.currentScreen[y][x]
+				pending := r.cellFor(c, x, y, r.pendingScreen[y][x])
 				if current != pending {
-					r.currentScreen[y][x] = pending
+					c.currentScreen[y][x] = pending
 
 					if !pending.PrevWide {
 						newCursor := ecma48.Cursor{
 							X: x, Y: y, Style: pending.Style,
 						}
 
-						delta := deltaMarkup(r.drawingCursor, newCursor)
+						delta := deltaMarkup(c.drawingCursor, newCursor)
 						diff.WriteString(delta)
 						diff.WriteString(string(pending.Rune))
 
@@ -261,7 +320,7 @@ func (r *Renderer) RenderSingleFrame() bool {
 							newCursor.X++
 						}
 
-						r.drawingCursor = newCursor
+						c.drawingCursor = newCursor
 					}
 				}
 				r.writingMutex.Unlock()
@@ -274,7 +333,7 @@ func (r *Renderer) RenderSingleFrame() bool {
 			diffBytes := []byte(diffStr)
 			// log.Println("Writing frame diff of length", len(diffBytes))
 			// log.Printf("Writing frame bytes: %q", diffBytes)
-			r.Write(diffBytes)
+			c.Write(diffBytes)
 		}
 
 		if fullyWritten {
@@ -293,20 +352,36 @@ func (r *Renderer) SetCursor(x, y int) {
 	}
 }
 
-func (r *Renderer) UpdateOut(out int) {
+// AddClient starts drawing to the host terminal at the given file descriptor.
+// A non-empty label is shown in the bottom right corner of that client only.
+func (r *Renderer) AddClient(id string, out int, label string) {
 	r.Pause <- true
-	r.OutFd = out
-	r.HardRefresh()
+	c := &client{fd: out, label: label}
+	r.clients[id] = c
+	r.hardRefreshClient(c)
+	r.Resume <- true
+}
+
+// RemoveClient stops drawing to the given client. It is a no-op for unknown clients.
+func (r *Renderer) RemoveClient(id string) {
+	r.Pause <- true
+	delete(r.clients, id)
 	r.Resume <- true
 }
 
 // HardRefresh force clears all cached chars. Used for handling terminal resize
 func (r *Renderer) HardRefresh() {
-	r.Write([]byte("\033[0m"))
-	r.Write([]byte("\033[2J"))
-	r.Write([]byte("\033[H"))
-	r.drawingCursor = ecma48.Cursor{}
-	r.currentScreen = expandBuffer([][]ecma48.StyledChar{}, r.w, r.h)
+	for _, c := range r.clients {
+		r.hardRefreshClient(c)
+	}
+}
+
+func (r *Renderer) hardRefreshClient(c *client) {
+	c.Write([]byte("\033[0m"))
+	c.Write([]byte("\033[2J"))
+	c.Write([]byte("\033[H"))
+	c.drawingCursor = ecma48.Cursor{}
+	c.currentScreen = expandBuffer([][]ecma48.StyledChar{}, r.w, r.h)
 
-	r.RenderSingleFrame()
+	r.renderClientFrame(c)
 }
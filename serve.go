@@ -1,20 +1,24 @@
 package main
 
 import (
-	"bufio"
 	"fmt"
+	"io/ioutil"
 	"log"
 	"net"
 	"runtime/debug"
+	"sync"
 	"syscall"
+	"time"
 
 	"github.com/aaronjanse/3mux/ecma48"
 	"github.com/aaronjanse/3mux/pane"
 	"github.com/aaronjanse/3mux/render"
 	"github.com/aaronjanse/3mux/wm"
-	"github.com/npat-efault/poller"
 )
 
+// readOnlyLabel is shown in the status bar of read-only clients
+const readOnlyLabel = " READ-ONLY "
+
 func serve(sessionInfo *SessionInfo) error {
 	log.Println("Booting...")
 
@@ -23,50 +27,106 @@ func serve(sessionInfo *SessionInfo) error {
 		return fmt.Errorf("Failed to load or generate config: %s", err)
 	}
 
-	renderer := render.NewRenderer(-1)
-	renderer.Resize(20, 20)
+	renderer := render.NewRenderer()
 	go renderer.Render()
+	renderer.Resize(20, 20)
 
 	shutdown := make(chan error)
 
-	newPane := func(renderer ecma48.Renderer) wm.Node {
-		return pane.NewPane(renderer, true, sessionInfo.uuid)
+	clients := newClientSet(sessionInfo, config.generalSettings.SessionSize)
+
+	newPane := func(renderer ecma48.Renderer, spec wm.PaneSpec) wm.Node {
+		return pane.NewPane(renderer, true, sessionInfo.uuid, config.generalSettings.theme, spec)
 	}
 
-	u := wm.NewUniverse(renderer,
-		config.generalSettings.EnableHelpBar,
-		config.generalSettings.EnableStatusBar,
-		func(err error) {
-			go func() {
-				if err != nil {
-					shutdown <- fmt.Errorf("%s\n%s", err, debug.Stack())
-				} else {
-					shutdown <- nil
-				}
-			}()
-		}, wm.Rect{X: 0, Y: 0, W: 50, H: 20}, newPane)
+	onDeath := func(err error) {
+		go func() {
+			if err != nil {
+				shutdown <- fmt.Errorf("%s\n%s", err, debug.Stack())
+			} else {
+				shutdown <- nil
+			}
+		}()
+	}
+
+	layout, err := loadSessionLayout(sessionInfo)
+	if err != nil {
+		log.Println("Ignoring layout:", err)
+	}
+
+	var u *wm.Universe
+	if layout != nil {
+		u = wm.NewUniverseFromLayout(renderer,
+			config.generalSettings.EnableHelpBar,
+			config.generalSettings.EnableStatusBar,
+			config.generalSettings.wmSettings(),
+			onDeath, wm.Rect{X: 0, Y: 0, W: 50, H: 20}, newPane, layout)
+	} else {
+		u = wm.NewUniverse(renderer,
+			config.generalSettings.EnableHelpBar,
+			config.generalSettings.EnableStatusBar,
+			config.generalSettings.wmSettings(),
+			onDeath, wm.Rect{X: 0, Y: 0, W: 50, H: 20}, newPane)
+	}
 	defer u.Kill()
 
-	stdin := make(chan ecma48.Output, 64)
-	defer close(stdin)
+	stdin := make(chan clientInput, 64)
+
+	// clients attach, detach, and resize on different goroutines, so the
+	// renderer and the universe are resized one client at a time
+	resizeMu := &sync.Mutex{}
+	resize := func() {
+		resizeMu.Lock()
+		defer resizeMu.Unlock()
 
-	var parser *ecma48.Parser
+		width, height, ok := clients.size()
+		if ok {
+			renderer.Resize(width, height)
+			u.SetRenderRect(0, 0, width, height)
+		}
+	}
 
-	var stdinBuf *bufio.Reader
-	var stdinPoller *poller.FD
-	listenFd(sessionInfo, func(stdinFd, stdoutFd int) {
-		stdinPoller, _ = poller.NewFD(stdinFd)
-		stdinBuf = bufio.NewReader(stdinPoller)
-		parser = ecma48.NewParser(true)
-		go parser.Parse(stdinBuf, stdin)
-		renderer.UpdateOut(stdoutFd)
+	// read-only clients are labelled at the right end of the status bar, so
+	// the selected pane's title is kept clear of it
+	updateStatusMargin := func() {
+		if config.generalSettings.EnableStatusBar && clients.hasReadOnly() {
+			u.SetStatusRightMargin(len(readOnlyLabel))
+		} else {
+			u.SetStatusRightMargin(0)
+		}
+	}
+
+	detachClient := func(id string) {
+		if clients.detach(id) {
+			log.Println("Detaching client", id)
+			renderer.RemoveClient(id)
+			updateStatusMargin()
+			resize()
+		}
+	}
+
+	listenFd(sessionInfo, func(clientID string, readOnly bool, stdinFd, stdoutFd int) {
+		log.Println("Attaching client", clientID, "read-only:", readOnly)
+		clients.attach(clientID, readOnly, stdinFd, stdin, func() {
+			detachClient(clientID)
+		})
+		label := ""
+		if readOnly && config.generalSettings.EnableStatusBar {
+			label = readOnlyLabel
+		}
+		updateStatusMargin()
+		renderer.AddClient(clientID, stdoutFd, label)
 	})
 
-	defer net.Dial("unix", sessionInfo.killClientPath)
+	defer func() {
+		for _, id := range clients.ids() {
+			clients.detach(id)
+		}
+	}()
 
-	listenResize(sessionInfo, func(width, height int) {
-		renderer.Resize(width, height)
-		u.SetRenderRect(0, 0, width, height)
+	listenResize(sessionInfo, func(clientID string, width, height int) {
+		clients.setSize(clientID, width, height)
+		resize()
 	})
 
 	go func() {
@@ -76,23 +136,38 @@ func serve(sessionInfo *SessionInfo) error {
 			panic(err)
 		}
 		for {
-			_, err = detachSocket.Accept()
+			conn, err := detachSocket.Accept()
 			if err != nil {
 				log.Println("Detach accept error:", err)
 				panic(err)
 			}
 
-			log.Println("Detaching...")
-
-			renderer.UpdateOut(-1)
-			parser.Shutdown <- nil
-			stdinPoller.Close()
+			idRaw, err := ioutil.ReadAll(conn)
+			conn.Close()
+			if err != nil {
+				log.Println("Detach read error:", err)
+				continue
+			}
 
-			log.Println("Detaching... DONE")
-			net.Dial("unix", sessionInfo.killClientPath)
+			id := string(idRaw)
+			switch {
+			case id == "":
+				// sent by `3mux detach --all`
+				for _, id := range clients.ids() {
+					detachClient(id)
+				}
+			case id == detachActive:
+				// the client that typed `3mux detach` was the last to send input
+				detachClient(clients.active())
+			default:
+				detachClient(id)
+			}
 		}
 	}()
 
+	controlRequests := make(chan pendingControl)
+	listenControl(sessionInfo, controlRequests)
+
 	go func() {
 		detachSocket, err := net.Listen("unix", sessionInfo.killServerPath)
 		if err != nil {
@@ -103,17 +178,37 @@ func serve(sessionInfo *SessionInfo) error {
 		shutdown <- nil
 	}()
 
+	var checkpoints <-chan time.Time
+	if interval := config.generalSettings.checkpointInterval; interval > 0 {
+		writeCheckpoint(sessionInfo, u, config.generalSettings.CheckpointScrollback)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		checkpoints = ticker.C
+	}
+
 	for {
 		select {
 		case next := <-stdin:
-			human := humanify(next)
+			if next.client.readOnly {
+				// read-only clients can still leave
+				if humanify(next.Output) == "Ctrl+Q" {
+					detachClient(next.client.id)
+				}
+				break
+			}
+
+			if clients.markActive(next.client.id) {
+				resize()
+			}
+
+			human := humanify(next.Output)
 			log.Println("Keypress:", human)
 
 			if human == "Ctrl+Q" {
 				return nil
 			}
 
-			if seiveMouseEvents(u, human, next) {
+			if seiveMouseEvents(u, human, next.Output) {
 				break
 			}
 			if seiveConfigEvents(config, u, human) {
@@ -122,7 +217,12 @@ func serve(sessionInfo *SessionInfo) error {
 
 			// if we didn't find anything special, just pass the raw data to
 			// the selected terminal
-			u.HandleStdin(next)
+			u.HandleStdin(next.Output)
+		case <-checkpoints:
+			writeCheckpoint(sessionInfo, u, config.generalSettings.CheckpointScrollback)
+		case req := <-controlRequests:
+			log.Println("Control:", req.Command, req.Args)
+			req.reply <- runControlRequest(u, req.controlRequest)
 		case err := <-shutdown:
 			if err != nil {
 				return err
@@ -132,7 +232,7 @@ func serve(sessionInfo *SessionInfo) error {
 	}
 }
 
-func listenResize(sessionInfo *SessionInfo, callback func(width, height int)) {
+func listenResize(sessionInfo *SessionInfo, callback func(clientID string, width, height int)) {
 	socket, err := net.Listen("unix", sessionInfo.resizePath)
 	if err != nil {
 		panic(err)
@@ -145,23 +245,26 @@ func listenResize(sessionInfo *SessionInfo, callback func(width, height int)) {
 				panic(err)
 			}
 
-			b := make([]byte, 4)
-			_, err = conn.Read(b)
+			// four bytes of size followed by the client ID
+			b, err := ioutil.ReadAll(conn)
+			conn.Close()
 			if err != nil {
 				panic(err)
 			}
+			if len(b) < 4 {
+				log.Printf("Ignoring malformed resize message: %q", b)
+				continue
+			}
 
 			width := (int(b[0]) << 8) + int(b[1])
 			height := (int(b[2]) << 8) + int(b[3])
 
-			callback(width, height)
-
-			conn.Close()
+			callback(string(b[4:]), width, height)
 		}
 	}()
 }
 
-func listenFd(sessionInfo *SessionInfo, callback func(stdinFd, stdoutFd int)) {
+func listenFd(sessionInfo *SessionInfo, callback func(clientID string, readOnly bool, stdinFd, stdoutFd int)) {
 	socket, err := net.Listen("unix", sessionInfo.fdPath)
 	if err != nil {
 		panic(err)
@@ -179,7 +282,13 @@ func listenFd(sessionInfo *SessionInfo, callback func(stdinFd, stdoutFd int)) {
 	}()
 }
 
-func parseFdConn(conn *net.UnixConn) (stdinFd, stdoutFd int) {
+// fdFlagReadOnly is set in the first byte sent alongside a client's fds if
+// the client should not be able to send input to the session
+const fdFlagReadOnly = 1 << 0
+
+// parseFdConn receives a client's stdin and stdout. They arrive alongside a
+// flags byte followed by the client ID.
+func parseFdConn(conn *net.UnixConn) (clientID string, readOnly bool, stdinFd, stdoutFd int) {
 	fConn, err := conn.File()
 	if err != nil {
 		panic(err)
@@ -187,7 +296,8 @@ func parseFdConn(conn *net.UnixConn) (stdinFd, stdoutFd int) {
 
 	numFds := 2
 	buf := make([]byte, syscall.CmsgSpace(numFds*4))
-	_, _, _, _, err = syscall.Recvmsg(int(fConn.Fd()), nil, buf, 0)
+	idBuf := make([]byte, 64)
+	n, _, _, _, err := syscall.Recvmsg(int(fConn.Fd()), idBuf, buf, 0)
 	if err != nil {
 		panic(err)
 	}
@@ -204,6 +314,10 @@ func parseFdConn(conn *net.UnixConn) (stdinFd, stdoutFd int) {
 		fds = append(fds, newFds...)
 	}
 
+	if n > 0 {
+		readOnly = idBuf[0]&fdFlagReadOnly != 0
+		clientID = string(idBuf[1:n])
+	}
 	stdinFd = fds[0]
 	stdoutFd = fds[1]
 	return
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aaronjanse/3mux/ecma48"
+	"github.com/aaronjanse/3mux/wm"
+)
+
+// ThemeConfig is the [theme] section of the config
+type ThemeConfig struct {
+	Border          *StyleConfig `toml:"border"`
+	ActiveBorder    *StyleConfig `toml:"active-border"`
+	MarkedBorder    *StyleConfig `toml:"marked-border"`
+	SyncBorder      *StyleConfig `toml:"sync-border"`
+	Tab             *StyleConfig `toml:"tab"`
+	ActiveTab       *StyleConfig `toml:"active-tab"`
+	StatusBar       *StyleConfig `toml:"status-bar"`
+	StatusBarActive *StyleConfig `toml:"status-bar-active"`
+	HelpBar         *StyleConfig `toml:"help-bar"`
+	SearchHighlight *StyleConfig `toml:"search-highlight"`
+	SizeIndicator   *StyleConfig `toml:"size-indicator"`
+
+	BorderChars string `toml:"border-chars"`
+	DimInactive bool   `toml:"dim-inactive"`
+}
+
+// StyleConfig is a style within the [theme] section, such as
+// `{ fg = "cyan", bold = true }`
+type StyleConfig struct {
+	Fg string `toml:"fg"`
+	Bg string `toml:"bg"`
+
+	Bold      bool `toml:"bold"`
+	Faint     bool `toml:"faint"`
+	Italic    bool `toml:"italic"`
+	Underline bool `toml:"underline"`
+	Reverse   bool `toml:"reverse"`
+}
+
+var colorNames = []string{"black", "red", "green", "yellow", "blue", "magenta", "cyan", "white"}
+
+// compileTheme fills in anything the [theme] section leaves out from the
+// default theme
+func compileTheme(c *ThemeConfig) (wm.Theme, error) {
+	theme := wm.DefaultTheme
+	if c == nil {
+		return theme, nil
+	}
+
+	styles := []struct {
+		name string
+		conf *StyleConfig
+		out  *ecma48.Style
+	}{
+		{"border", c.Border, &theme.Border},
+		{"active-border", c.ActiveBorder, &theme.ActiveBorder},
+		{"marked-border", c.MarkedBorder, &theme.MarkedBorder},
+		{"sync-border", c.SyncBorder, &theme.SyncBorder},
+		{"tab", c.Tab, &theme.Tab},
+		{"active-tab", c.ActiveTab, &theme.ActiveTab},
+		{"status-bar", c.StatusBar, &theme.StatusBar},
+		{"status-bar-active", c.StatusBarActive, &theme.StatusBarActive},
+		{"help-bar", c.HelpBar, &theme.HelpBar},
+		{"search-highlight", c.SearchHighlight, &theme.SearchHighlight},
+		{"size-indicator", c.SizeIndicator, &theme.SizeIndicator},
+	}
+	for _, s := range styles {
+		if s.conf == nil {
+			continue
+		}
+		style, err := s.conf.compile()
+		if err != nil {
+			return theme, fmt.Errorf("Invalid theme style `%s`: %s", s.name, err)
+		}
+		*s.out = style
+	}
+
+	if c.BorderChars != "" {
+		chars, ok := wm.BorderCharSets[c.BorderChars]
+		if !ok {
+			return theme, fmt.Errorf("Invalid border-chars `%s`: expected `single`, `rounded`, `heavy`, `double`, or `ascii`", c.BorderChars)
+		}
+		theme.Chars = chars
+	}
+	theme.DimInactive = c.DimInactive
+
+	return theme, nil
+}
+
+func (c *StyleConfig) compile() (ecma48.Style, error) {
+	fg, err := parseColor(c.Fg)
+	if err != nil {
+		return ecma48.Style{}, err
+	}
+	bg, err := parseColor(c.Bg)
+	if err != nil {
+		return ecma48.Style{}, err
+	}
+	return ecma48.Style{
+		Fg:        fg,
+		Bg:        bg,
+		Bold:      c.Bold,
+		Faint:     c.Faint,
+		Italic:    c.Italic,
+		Underline: c.Underline,
+		Reverse:   c.Reverse,
+	}, nil
+}
+
+// parseColor reads a color name like `cyan` or `bright-black`, a 256-color
+// palette index like `208`, or a truecolor hex code like `#ff8800`
+func parseColor(s string) (ecma48.Color, error) {
+	if s == "" || s == "default" {
+		return ecma48.Color{ColorMode: ecma48.ColorNone}, nil
+	}
+
+	if strings.HasPrefix(s, "#") {
+		rgb, err := strconv.ParseUint(s[1:], 16, 32)
+		if err != nil || len(s) != 7 {
+			return ecma48.Color{}, fmt.Errorf("expected a hex color like `#ff8800`, got `%s`", s)
+		}
+		return ecma48.Color{ColorMode: ecma48.ColorBit24, Code: int32(rgb)}, nil
+	}
+
+	if n, err := strconv.Atoi(s); err == nil {
+		if n < 0 || n > 255 {
+			return ecma48.Color{}, fmt.Errorf("expected a color number from 0 to 255, got `%s`", s)
+		}
+		return ecma48.Color{ColorMode: ecma48.ColorBit8, Code: int32(n)}, nil
+	}
+
+	mode := ecma48.ColorBit3Normal
+	name := s
+	if strings.HasPrefix(s, "bright-") {
+		mode = ecma48.ColorBit3Bright
+		name = strings.TrimPrefix(s, "bright-")
+	}
+	for code, colorName := range colorNames {
+		if name == colorName {
+			return ecma48.Color{ColorMode: mode, Code: int32(code)}, nil
+		}
+	}
+	return ecma48.Color{}, fmt.Errorf("unknown color `%s`", s)
+}
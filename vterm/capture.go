@@ -0,0 +1,172 @@
+package vterm
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"github.com/aaronjanse/3mux/ecma48"
+)
+
+// LineCount returns how many lines of scrollback and screen are available to
+// Capture. Lines are numbered from -len(Scrollback) up to h-1, with line 0 at
+// the top of the screen.
+func (v *VTerm) LineCount() (scrollback, screen int) {
+	v.inSync(func() {
+		scrollback, screen = len(v.Scrollback), v.h
+	})
+	return
+}
+
+// Capture returns the text of lines start through end (inclusive). When
+// styled is true, SGR escape codes are included so the output looks the same
+// as it does on screen.
+func (v *VTerm) Capture(start, end int, styled bool) (out string) {
+	v.inSync(func() {
+		out = v.capture(v.Scrollback, v.Screen, start, end, styled)
+	})
+	return
+}
+
+// History returns up to maxLines of scrollback followed by the primary screen,
+// with styling, even while a program is using the alternate screen. Blank
+// lines at either end are left out.
+func (v *VTerm) History(maxLines int) (out string) {
+	v.inSync(func() {
+		screen := v.Screen
+		if v.UsingAltScreen {
+			screen = v.screenBackup
+		}
+		start := -len(v.Scrollback)
+		if start < -maxLines {
+			start = -maxLines
+		}
+		out = strings.Trim(v.capture(v.Scrollback, screen, start, v.h-1, true), "\n")
+	})
+	return
+}
+
+// inSync runs f on the goroutine running ProcessStdout, so that f can read
+// the screen and scrollback while the program is writing to them. Before
+// ProcessStdout starts or once it has returned, f runs on the calling
+// goroutine.
+func (v *VTerm) inSync(f func()) {
+	if atomic.LoadInt32(&v.started) == 0 {
+		f()
+		return
+	}
+	done := make(chan struct{})
+	select {
+	case v.syncRequests <- func() {
+		f()
+		close(done)
+	}:
+		<-done
+	case <-v.stopped:
+		f()
+	}
+}
+
+func (v *VTerm) capture(scrollback, screen [][]ecma48.StyledChar, start, end int, styled bool) string {
+	if start < -len(scrollback) {
+		start = -len(scrollback)
+	}
+	if end > v.h-1 {
+		end = v.h - 1
+	}
+
+	var out strings.Builder
+	for y := start; y <= end; y++ {
+		var line []ecma48.StyledChar
+		if y < 0 {
+			line = scrollback[len(scrollback)+y]
+		} else if y < len(screen) {
+			line = screen[y]
+		}
+		if len(line) > v.w {
+			line = line[:v.w]
+		}
+
+		if styled {
+			out.WriteString(captureStyled(line))
+		} else {
+			out.WriteString(capturePlain(line))
+		}
+		out.WriteRune('\n')
+	}
+	return out.String()
+}
+
+func capturePlain(line []ecma48.StyledChar) string {
+	var out strings.Builder
+	for _, ch := range line {
+		if ch.PrevWide {
+			continue
+		}
+		if ch.Rune == 0 {
+			out.WriteRune(' ')
+		} else {
+			out.WriteRune(ch.Rune)
+		}
+	}
+	return strings.TrimRight(out.String(), " ")
+}
+
+func captureStyled(line []ecma48.StyledChar) string {
+	// trailing blanks are only worth keeping if they're visibly styled
+	last := len(line) - 1
+	for ; last >= 0; last-- {
+		ch := line[last]
+		blank := ch.Rune == 0 || ch.Rune == ' '
+		if !blank || ch.Bg.ColorMode != ecma48.ColorNone || ch.Reverse {
+			break
+		}
+	}
+
+	var out strings.Builder
+	current := ecma48.Style{}
+	for _, ch := range line[:last+1] {
+		if ch.PrevWide {
+			continue
+		}
+		if ch.Style != current {
+			out.WriteString(styleMarkup(ch.Style))
+			current = ch.Style
+		}
+		if ch.Rune == 0 {
+			out.WriteRune(' ')
+		} else {
+			out.WriteRune(ch.Rune)
+		}
+	}
+	if current != (ecma48.Style{}) {
+		out.WriteString("\x1b[m")
+	}
+	return out.String()
+}
+
+// styleMarkup returns the SGR codes that reset the host terminal to the given style
+func styleMarkup(s ecma48.Style) string {
+	codes := []string{"0"}
+	flags := []struct {
+		on   bool
+		code string
+	}{
+		{s.Bold, "1"}, {s.Faint, "2"}, {s.Italic, "3"}, {s.Underline, "4"},
+		{s.Reverse, "7"}, {s.Conceal, "8"}, {s.CrossedOut, "9"},
+	}
+	for _, f := range flags {
+		if f.on {
+			codes = append(codes, f.code)
+		}
+	}
+	out := fmt.Sprintf("\x1b[%sm", strings.Join(codes, ";"))
+
+	if s.Fg.ColorMode != ecma48.ColorNone {
+		out += s.Fg.ToANSI(false)
+	}
+	if s.Bg.ColorMode != ecma48.ColorNone {
+		out += s.Bg.ToANSI(true)
+	}
+	return out
+}
@@ -0,0 +1,149 @@
+package vterm
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"github.com/aaronjanse/3mux/ecma48"
+)
+
+// mouse reporting modes, named by the DECSET codes that turn them on
+const (
+	mouseNone = 0
+	// mouseNormal reports presses, releases, and the scroll wheel
+	mouseNormal = 1000
+	// mouseButtonEvent also reports motion while a button is held
+	mouseButtonEvent = 1002
+	// mouseAnyEvent also reports motion with no button held
+	mouseAnyEvent = 1003
+)
+
+// mouse encodings, named by the DECSET codes that turn them on
+const (
+	mouseEncodingDefault = 0
+	mouseEncodingSGR     = 1006
+	mouseEncodingURXVT   = 1015
+)
+
+// alternateScroll is the DECSET code for sending the scroll wheel as arrow
+// keys while the alt screen is shown. It is on unless the program turns it off.
+const alternateScroll = 1007
+
+// appCursorKeys is the DECSET code (DECCKM) for making the arrow keys send
+// SS3 sequences rather than CSI ones
+const appCursorKeys = 1
+
+func (v *VTerm) setMouseMode(code int, on bool) {
+	if on {
+		atomic.StoreInt32(&v.mouseMode, int32(code))
+	} else {
+		atomic.StoreInt32(&v.mouseMode, mouseNone)
+	}
+}
+
+func (v *VTerm) setMouseEncoding(code int, on bool) {
+	if on {
+		atomic.StoreInt32(&v.mouseEncoding, int32(code))
+	} else {
+		atomic.CompareAndSwapInt32(&v.mouseEncoding, int32(code), mouseEncodingDefault)
+	}
+}
+
+// EncodeMouse returns a mouse event, in the vterm's own coordinates, encoded
+// as the program asked for, or nil if the program hasn't asked for such events
+func (v *VTerm) EncodeMouse(ev ecma48.Parsed) []byte {
+	mode := atomic.LoadInt32(&v.mouseMode)
+	if mode == mouseNone {
+		return nil
+	}
+
+	var x, y, code int
+	var mods ecma48.MouseMods
+	release := false
+	switch e := ev.(type) {
+	case ecma48.MouseDown:
+		x, y, code, mods = e.X, e.Y, int(e.Button), e.MouseMods
+	case ecma48.MouseUp:
+		x, y, code, mods = e.X, e.Y, int(e.Button), e.MouseMods
+		release = true
+	case ecma48.ScrollDown:
+		x, y, code, mods = e.X, e.Y, 64, e.MouseMods
+	case ecma48.ScrollUp:
+		x, y, code, mods = e.X, e.Y, 65, e.MouseMods
+	case ecma48.MouseDrag:
+		if mode != mouseButtonEvent && mode != mouseAnyEvent {
+			return nil
+		}
+		x, y, code, mods = e.X, e.Y, 32+int(e.Button), e.MouseMods
+	case ecma48.MouseMove:
+		if mode != mouseAnyEvent {
+			return nil
+		}
+		x, y, code, mods = e.X, e.Y, 32+3, e.MouseMods
+	default:
+		return nil
+	}
+
+	if mods.Shift {
+		code |= 4
+	}
+	if mods.Alt {
+		code |= 8
+	}
+	if mods.Ctrl {
+		code |= 16
+	}
+
+	// a drag can wander outside the vterm
+	if x < 0 {
+		x = 0
+	} else if x >= v.w {
+		x = v.w - 1
+	}
+	if y < 0 {
+		y = 0
+	} else if y >= v.h {
+		y = v.h - 1
+	}
+
+	encoding := atomic.LoadInt32(&v.mouseEncoding)
+	if encoding == mouseEncodingSGR {
+		final := 'M'
+		if release {
+			final = 'm'
+		}
+		return []byte(fmt.Sprintf("\x1b[<%d;%d;%d%c", code, x+1, y+1, final))
+	}
+
+	// other encodings don't say which button was released
+	if release {
+		code |= 3
+	}
+	if encoding == mouseEncodingURXVT {
+		return []byte(fmt.Sprintf("\x1b[%d;%d;%dM", code+32, x+1, y+1))
+	}
+	if x+1+32 > 255 || y+1+32 > 255 {
+		// too far to fit in a byte
+		return nil
+	}
+	return []byte{'\x1b', '[', 'M', byte(code + 32), byte(x + 1 + 32), byte(y + 1 + 32)}
+}
+
+// WheelKeys returns the arrow keys that a turn of the scroll wheel is sent as
+// while the alt screen is shown, or nil if the program turned this off. They
+// are encoded as the program asked for with DECCKM.
+func (v *VTerm) WheelKeys(d ecma48.Direction, lines int) []byte {
+	if atomic.LoadInt32(&v.alternateScrollOff) == 1 {
+		return nil
+	}
+	intro := "\x1b["
+	if atomic.LoadInt32(&v.appCursorKeys) == 1 {
+		intro = "\x1bO"
+	}
+	final := "A"
+	if d == ecma48.Down {
+		final = "B"
+	}
+	return []byte(strings.Repeat(intro+final, lines))
+}
@@ -0,0 +1,148 @@
+package vterm
+
+import (
+	"testing"
+
+	"github.com/aaronjanse/3mux/ecma48"
+)
+
+func TestEncodeMouse(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     int32
+		encoding int32
+		ev       ecma48.Parsed
+		want     string
+	}{
+		{
+			name: "tracking off",
+			mode: mouseNone,
+			ev:   ecma48.MouseDown{X: 4, Y: 2},
+		},
+		{
+			name: "X10 press",
+			mode: mouseNormal,
+			ev:   ecma48.MouseDown{X: 4, Y: 2, Button: ecma48.RightButton},
+			want: "\x1b[M\"%#",
+		},
+		{
+			name: "X10 release",
+			mode: mouseNormal,
+			ev:   ecma48.MouseUp{X: 4, Y: 2, Button: ecma48.LeftButton},
+			want: "\x1b[M#%#",
+		},
+		{
+			name: "X10 scroll with ctrl",
+			mode: mouseNormal,
+			ev:   ecma48.ScrollUp{X: 0, Y: 0, MouseMods: ecma48.MouseMods{Ctrl: true}},
+			want: "\x1b[Mq!!",
+		},
+		{
+			name: "X10 beyond column 223",
+			mode: mouseNormal,
+			ev:   ecma48.MouseDown{X: 250, Y: 2},
+		},
+		{
+			name:     "SGR press",
+			mode:     mouseNormal,
+			encoding: mouseEncodingSGR,
+			ev:       ecma48.MouseDown{X: 4, Y: 2, MouseMods: ecma48.MouseMods{Shift: true}},
+			want:     "\x1b[<4;5;3M",
+		},
+		{
+			name:     "SGR release",
+			mode:     mouseNormal,
+			encoding: mouseEncodingSGR,
+			ev:       ecma48.MouseUp{X: 4, Y: 2, Button: ecma48.MiddleButton},
+			want:     "\x1b[<1;5;3m",
+		},
+		{
+			name:     "SGR beyond column 223",
+			mode:     mouseNormal,
+			encoding: mouseEncodingSGR,
+			ev:       ecma48.ScrollDown{X: 250, Y: 2},
+			want:     "\x1b[<64;251;3M",
+		},
+		{
+			name:     "URXVT press",
+			mode:     mouseNormal,
+			encoding: mouseEncodingURXVT,
+			ev:       ecma48.MouseDown{X: 4, Y: 2},
+			want:     "\x1b[32;5;3M",
+		},
+		{
+			name:     "URXVT release",
+			mode:     mouseNormal,
+			encoding: mouseEncodingURXVT,
+			ev:       ecma48.MouseUp{X: 4, Y: 2, Button: ecma48.RightButton},
+			want:     "\x1b[35;5;3M",
+		},
+		{
+			name: "drag without button events",
+			mode: mouseNormal,
+			ev:   ecma48.MouseDrag{X: 4, Y: 2},
+		},
+		{
+			name:     "drag outside the vterm",
+			mode:     mouseButtonEvent,
+			encoding: mouseEncodingSGR,
+			ev:       ecma48.MouseDrag{X: -3, Y: 400},
+			want:     "\x1b[<32;1;24M",
+		},
+		{
+			name: "move without any-event tracking",
+			mode: mouseButtonEvent,
+			ev:   ecma48.MouseMove{X: 4, Y: 2},
+		},
+		{
+			name:     "move",
+			mode:     mouseAnyEvent,
+			encoding: mouseEncodingSGR,
+			ev:       ecma48.MouseMove{X: 4, Y: 2},
+			want:     "\x1b[<35;5;3M",
+		},
+		{
+			name: "not a mouse event",
+			mode: mouseAnyEvent,
+			ev:   ecma48.Char{Rune: 'x'},
+		},
+	}
+
+	for _, tt := range tests {
+		v := &VTerm{w: 300, h: 24, mouseMode: tt.mode, mouseEncoding: tt.encoding}
+		got := v.EncodeMouse(tt.ev)
+		if string(got) != tt.want {
+			t.Errorf("%s: EncodeMouse(%#v) = %q, want %q", tt.name, tt.ev, got, tt.want)
+		}
+		if tt.want == "" && got != nil {
+			t.Errorf("%s: EncodeMouse(%#v) = %q, want nil", tt.name, tt.ev, got)
+		}
+	}
+}
+
+func TestWheelKeys(t *testing.T) {
+	tests := []struct {
+		name      string
+		scrollOff int32
+		appKeys   int32
+		d         ecma48.Direction
+		lines     int
+		want      string
+	}{
+		{name: "up", d: ecma48.Up, lines: 3, want: "\x1b[A\x1b[A\x1b[A"},
+		{name: "down", d: ecma48.Down, lines: 1, want: "\x1b[B"},
+		{name: "application keys", appKeys: 1, d: ecma48.Up, lines: 2, want: "\x1bOA\x1bOA"},
+		{name: "alternate scroll off", scrollOff: 1, d: ecma48.Down, lines: 3},
+	}
+
+	for _, tt := range tests {
+		v := &VTerm{alternateScrollOff: tt.scrollOff, appCursorKeys: tt.appKeys}
+		got := v.WheelKeys(tt.d, tt.lines)
+		if string(got) != tt.want {
+			t.Errorf("%s: WheelKeys = %q, want %q", tt.name, got, tt.want)
+		}
+		if tt.want == "" && got != nil {
+			t.Errorf("%s: WheelKeys = %q, want nil", tt.name, got)
+		}
+	}
+}
@@ -11,20 +11,26 @@ func (v *VTerm) ScrollbackReset() {
 	}
 }
 
-// ScrollbackUp shifts the screen contents up, with scrollback
-func (v *VTerm) ScrollbackUp() {
+// ScrollbackUp shifts the screen contents up by the given number of lines,
+// with scrollback
+func (v *VTerm) ScrollbackUp(lines int) {
 	if v.UsingAltScreen {
 		return
 	}
 
-	if v.ScrollbackPos-5 >= 0 {
-		v.ScrollbackPos -= 5
+	pos := v.ScrollbackPos - lines
+	if pos < 0 {
+		pos = 0
+	}
+	if pos != v.ScrollbackPos {
+		v.ScrollbackPos = pos
 		v.RedrawWindow()
 	}
 }
 
-// ScrollbackDown shifts the screen contents down, with scrollback
-func (v *VTerm) ScrollbackDown() {
+// ScrollbackDown shifts the screen contents down by the given number of
+// lines, with scrollback
+func (v *VTerm) ScrollbackDown(lines int) {
 	if v.UsingAltScreen {
 		return
 	}
@@ -33,8 +39,12 @@ func (v *VTerm) ScrollbackDown() {
 		return
 	}
 
-	if v.ScrollbackPos+5 < len(v.Scrollback) {
-		v.ScrollbackPos += 5
+	pos := v.ScrollbackPos + lines
+	if pos > len(v.Scrollback)-1 {
+		pos = len(v.Scrollback) - 1
+	}
+	if pos != v.ScrollbackPos {
+		v.ScrollbackPos = pos
 		v.RedrawWindow()
 	}
 }
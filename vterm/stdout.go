@@ -28,6 +28,9 @@ const (
 )
 
 func (v *VTerm) ProcessStdout(input *bufio.Reader) {
+	atomic.StoreInt32(&v.started, 1)
+	defer close(v.stopped)
+
 	stdout := make(chan ecma48.Output, 3200000)
 	shutdown := make(chan bool)
 
@@ -46,8 +49,14 @@ func (v *VTerm) ProcessStdout(input *bufio.Reader) {
 				if !p {
 					break
 				}
-				p = <-v.ChangePause
+				select {
+				case p = <-v.ChangePause:
+				case f := <-v.syncRequests:
+					f()
+				}
 			}
+		case f := <-v.syncRequests:
+			f()
 		case <-shutdown:
 			return
 		case output := <-stdout:
@@ -136,6 +145,27 @@ func (v *VTerm) ProcessStdout(input *bufio.Reader) {
 						}
 					}
 					v.UsingAltScreen = x.On
+					if x.On {
+						atomic.StoreInt32(&v.altScreen, 1)
+					} else {
+						atomic.StoreInt32(&v.altScreen, 0)
+					}
+				case mouseNormal, mouseButtonEvent, mouseAnyEvent:
+					v.setMouseMode(x.Code, x.On)
+				case mouseEncodingSGR, mouseEncodingURXVT:
+					v.setMouseEncoding(x.Code, x.On)
+				case alternateScroll:
+					if x.On {
+						atomic.StoreInt32(&v.alternateScrollOff, 0)
+					} else {
+						atomic.StoreInt32(&v.alternateScrollOff, 1)
+					}
+				case appCursorKeys:
+					if x.On {
+						atomic.StoreInt32(&v.appCursorKeys, 1)
+					} else {
+						atomic.StoreInt32(&v.appCursorKeys, 0)
+					}
 				default:
 					log.Printf("Unrecognized DEC Private Mode: %d", x.Code)
 				}
@@ -278,6 +308,14 @@ func (v *VTerm) ProcessStdout(input *bufio.Reader) {
 			case ecma48.StyleUnderline:
 				v.Cursor.Style.Underline = bool(x)
 
+			case ecma48.WindowTitle:
+				if x.WindowName && x.Title != v.Title() {
+					v.title.Store(x.Title)
+					if v.OnTitleChange != nil {
+						v.OnTitleChange()
+					}
+				}
+
 			case ecma48.Unrecognized:
 				log.Printf("?? %q", output.Raw)
 			default:
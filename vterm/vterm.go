@@ -6,6 +6,8 @@ A Char is a character printed using a given cursor (which is stored alongside th
 package vterm
 
 import (
+	"sync/atomic"
+
 	"github.com/aaronjanse/3mux/ecma48"
 )
 
@@ -54,9 +56,35 @@ type VTerm struct {
 
 	scrollingRegion ScrollingRegion
 
+	// title is the string last set by OSC 0 or 2. It is read from other
+	// goroutines, so it is accessed atomically.
+	title atomic.Value
+	// OnTitleChange is called whenever the program running in the vterm
+	// changes its title
+	OnTitleChange func()
+
+	// mouseMode and mouseEncoding are the DECSET codes of the mouse reporting
+	// the program asked for and how it is encoded, or 0 for none and the
+	// default encoding. They are read from other goroutines, so they are
+	// accessed atomically.
+	mouseMode, mouseEncoding int32
+	// alternateScrollOff is 1 once the program turns off DECSET 1007
+	alternateScrollOff int32
+	// appCursorKeys is 1 while the program has DECCKM turned on
+	appCursorKeys int32
+	// altScreen is 1 while UsingAltScreen is set, for other goroutines
+	altScreen int32
+
 	ChangePause   chan bool
 	IsPaused      bool
 	DebugSlowMode bool
+
+	// syncRequests are run by ProcessStdout between outputs, so that they can
+	// read the buffers it writes to. started is set atomically once it runs,
+	// and stopped is closed once it returns.
+	syncRequests chan func()
+	started      int32
+	stopped      chan struct{}
 }
 
 // NewVTerm returns a VTerm ready to be used by its exported methods
@@ -92,11 +120,25 @@ func NewVTerm(renderer ecma48.Renderer, parentSetCursor func(x, y int)) *VTerm {
 		ChangePause:      make(chan bool, 1),
 		IsPaused:         false,
 		DebugSlowMode:    false,
+		syncRequests:     make(chan func()),
+		stopped:          make(chan struct{}),
 	}
 
 	return v
 }
 
+// InAltScreen returns whether the program running in the vterm is showing the
+// alt screen. Unlike UsingAltScreen, it is safe to call from any goroutine.
+func (v *VTerm) InAltScreen() bool {
+	return atomic.LoadInt32(&v.altScreen) == 1
+}
+
+// Title returns the title set by the program running in the vterm, if any
+func (v *VTerm) Title() string {
+	title, _ := v.title.Load().(string)
+	return title
+}
+
 // Kill safely shuts down all vterm processes for the instance
 func (v *VTerm) Kill() {
 	v.usingSlowRefresh = false
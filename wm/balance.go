@@ -0,0 +1,65 @@
+package wm
+
+// BalanceSplits evens out the sizes of every split in the current workspace
+func (u *Universe) BalanceSplits() {
+	u.wmOpMutex.Lock()
+	defer u.wmOpMutex.Unlock()
+
+	u.workspaces[u.selectionIdx].contents.balance(true)
+	u.refreshRenderRect()
+}
+
+// BalanceParentSplit evens out the sizes of the selected pane and its siblings
+func (u *Universe) BalanceParentSplit() {
+	u.wmOpMutex.Lock()
+	defer u.wmOpMutex.Unlock()
+
+	u.workspaces[u.selectionIdx].contents.selectedParent().balance(false)
+	u.refreshRenderRect()
+}
+
+// autoBalance balances the current workspace if the settings ask for it
+func (u *Universe) autoBalance() {
+	if u.settings.AutoBalance {
+		u.workspaces[u.selectionIdx].contents.balance(true)
+	}
+}
+
+// balance sizes a split's children by how many panes they show, so that
+// every pane ends up with about the same space
+func (s *split) balance(recursive bool) {
+	total := 0
+	for _, e := range s.elements {
+		total += balanceWeight(e.contents)
+	}
+	for idx, e := range s.elements {
+		s.elements[idx].size = float32(balanceWeight(e.contents)) / float32(total)
+		if child, ok := e.contents.(*split); ok && recursive {
+			child.balance(true)
+		}
+	}
+}
+
+// balanceWeight is the number of panes a node shows side by side or stacked.
+// Tabbed and stacked splits show one child at a time, so they weigh as much
+// as their heaviest child.
+func balanceWeight(n Node) int {
+	s, ok := n.(*split)
+	if !ok {
+		return 1
+	}
+
+	weight := 0
+	for _, e := range s.elements {
+		w := balanceWeight(e.contents)
+		if s.mode == modeSplit {
+			weight += w
+		} else if w > weight {
+			weight = w
+		}
+	}
+	if weight == 0 {
+		return 1
+	}
+	return weight
+}
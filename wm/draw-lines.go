@@ -9,6 +9,9 @@ func (u *Universe) getSelectedNode() Node {
 }
 
 func (s *workspace) getSelectedNode() Node {
+	if f := s.selectedFloat(); f != nil {
+		return f.contents
+	}
 	return s.contents.getSelectedNode()
 }
 
@@ -26,63 +29,91 @@ func (s *split) getSelectedNode() Node {
 }
 
 func (u *Universe) drawSelectionBorder() {
+	w := u.workspaces[u.selectionIdx]
+	if !w.doFullscreen {
+		// titles take precedence over the border
+		defer w.contents.redrawTitles()
+		defer w.drawPaneTitles()
+	}
+
 	// don't draw when there's only one pane
-	if len(u.workspaces[u.selectionIdx].contents.elements) == 1 {
+	if len(w.contents.elements) == 1 {
 		return
 	}
-	maxH := u.workspaces[u.selectionIdx].contents.GetRenderRect().H
 
-	r := u.getSelectedNode().GetRenderRect()
+	// synchronized panes' borders go first so that the selection's wins
+	// where they meet
+	if !w.doFullscreen {
+		tiled := w.contents.leaves()
+		for _, n := range w.syncTargets() {
+			for _, t := range tiled {
+				if n == t {
+					u.drawBorder(w, n.GetRenderRect(), u.settings.Theme.SyncBorder)
+				}
+			}
+		}
+	}
 
-	style := ecma48.Style{
-		Fg: ecma48.Color{
-			ColorMode: ecma48.ColorBit3Normal,
-			Code:      6,
-		},
+	// the marked pane's border goes next for the same reason
+	if m := u.visibleMark(w); m != nil && !w.doFullscreen {
+		u.drawBorder(w, m.GetRenderRect(), u.settings.Theme.MarkedBorder)
 	}
 
+	// a floating pane's border shows the selection instead
+	if w.floatFocused {
+		return
+	}
+
+	u.drawBorder(w, w.selectedTarget().GetRenderRect(), u.settings.Theme.ActiveBorder)
+}
+
+// drawBorder draws a box around a tiled pane
+func (u *Universe) drawBorder(w *workspace, r Rect, style ecma48.Style) {
+	maxH := w.renderRect.Y + w.renderRect.H
+	chars := u.settings.Theme.Chars
+
 	for i := 0; i <= r.H; i++ {
 		if r.Y+i < maxH {
 			ch := ecma48.PositionedChar{
-				Rune: '│',
+				Rune: chars.Vertical,
 				Cursor: ecma48.Cursor{
 					X:     r.X - 1,
 					Y:     r.Y + i,
 					Style: style,
 				},
 			}
-			u.renderer.HandleCh(ch)
+			w.renderer.HandleCh(ch)
 		}
 	}
 	for i := 0; i <= r.H; i++ {
 		if r.Y+i < maxH {
 			ch := ecma48.PositionedChar{
-				Rune: '│',
+				Rune: chars.Vertical,
 				Cursor: ecma48.Cursor{
 					X:     r.X + r.W,
 					Y:     r.Y + i,
 					Style: style,
 				},
 			}
-			u.renderer.HandleCh(ch)
+			w.renderer.HandleCh(ch)
 		}
 	}
 	for i := 0; i <= r.W; i++ {
 		ch := ecma48.PositionedChar{
-			Rune: '─',
+			Rune: chars.Horizontal,
 			Cursor: ecma48.Cursor{
 				X:     r.X + i,
 				Y:     r.Y - 1,
 				Style: style,
 			},
 		}
-		u.renderer.HandleCh(ch)
+		w.renderer.HandleCh(ch)
 	}
 
 	if r.Y+r.H < maxH {
 		for i := 0; i <= r.W; i++ {
 			ch := ecma48.PositionedChar{
-				Rune: '─',
+				Rune: chars.Horizontal,
 				Cursor: ecma48.Cursor{
 					X:     r.X + i,
 					Y:     r.Y + r.H,
@@ -90,49 +121,49 @@ func (u *Universe) drawSelectionBorder() {
 				},
 			}
 
-			u.renderer.HandleCh(ch)
+			w.renderer.HandleCh(ch)
 		}
 	}
 
 	ch := ecma48.PositionedChar{
-		Rune: '┌',
+		Rune: chars.TopLeft,
 		Cursor: ecma48.Cursor{
 			X:     r.X - 1,
 			Y:     r.Y - 1,
 			Style: style,
 		},
 	}
-	u.renderer.HandleCh(ch)
+	w.renderer.HandleCh(ch)
 
 	ch = ecma48.PositionedChar{
-		Rune: '┐',
+		Rune: chars.TopRight,
 		Cursor: ecma48.Cursor{
 			X:     r.X + r.W,
 			Y:     r.Y - 1,
 			Style: style,
 		},
 	}
-	u.renderer.HandleCh(ch)
+	w.renderer.HandleCh(ch)
 
 	if r.Y+r.H < maxH {
 		ch = ecma48.PositionedChar{
-			Rune: '└',
+			Rune: chars.BottomLeft,
 			Cursor: ecma48.Cursor{
 				X:     r.X - 1,
 				Y:     r.Y + r.H,
 				Style: style,
 			},
 		}
-		u.renderer.HandleCh(ch)
+		w.renderer.HandleCh(ch)
 
 		ch = ecma48.PositionedChar{
-			Rune: '┘',
+			Rune: chars.BottomRight,
 			Cursor: ecma48.Cursor{
 				X:     r.X + r.W,
 				Y:     r.Y + r.H,
 				Style: style,
 			},
 		}
-		u.renderer.HandleCh(ch)
+		w.renderer.HandleCh(ch)
 	}
 }
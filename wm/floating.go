@@ -0,0 +1,388 @@
+package wm
+
+import (
+	"errors"
+
+	"github.com/aaronjanse/3mux/ecma48"
+)
+
+// A floatingPane sits above a workspace's tiled panes
+type floatingPane struct {
+	contents Node
+	// rect includes the border drawn around the pane
+	rect Rect
+	// scratchpad is set for panes that belong in the scratchpad
+	scratchpad bool
+	// popup is set for panes that close once their command exits, which
+	// layouts leave out since the command may not be safe to run again
+	popup bool
+}
+
+// inner returns the area of a floating pane inside its border
+func (f *floatingPane) inner() Rect {
+	return Rect{X: f.rect.X + 1, Y: f.rect.Y + 1, W: f.rect.W - 2, H: f.rect.H - 2}
+}
+
+// smallest size of a floating pane, including its border
+const (
+	minFloatW = 10
+	minFloatH = 4
+)
+
+// floatStep is how far moving or resizing a floating pane with keys goes
+const floatStep = 2
+
+func (r Rect) contains(x, y int) bool {
+	return r.X <= x && x < r.X+r.W && r.Y <= y && y < r.Y+r.H
+}
+
+// covered returns whether any of the rects contains the given point
+func covered(rects []Rect, x, y int) bool {
+	for _, r := range rects {
+		if r.contains(x, y) {
+			return true
+		}
+	}
+	return false
+}
+
+// ToggleFloating floats the selected pane above the tiled ones, or tiles it
+// again if it is already floating
+func (u *Universe) ToggleFloating() error {
+	u.wmOpMutex.Lock()
+	defer u.wmOpMutex.Unlock()
+
+	w := u.workspaces[u.selectionIdx]
+	if w.doFullscreen {
+		return errors.New("cannot float a pane while one is fullscreen")
+	}
+
+	if f := w.selectedFloat(); f != nil {
+		w.removeFloat(f)
+		w.tile(f.contents)
+	} else {
+		if len(w.contents.leaves()) <= 1 {
+			return errors.New("cannot float the only tiled pane")
+		}
+		p := w.detachSelected()
+		if p == nil {
+			return errors.New("no pane to float")
+		}
+		w.addFloat(p, w.centeredRect(60))
+	}
+
+	u.simplify()
+	u.refreshRenderRect()
+	u.updateSelection()
+	return nil
+}
+
+// ToggleFloatingFocus moves the selection between the tiled panes and the
+// topmost floating pane
+func (u *Universe) ToggleFloatingFocus() {
+	u.wmOpMutex.Lock()
+	defer u.wmOpMutex.Unlock()
+
+	w := u.workspaces[u.selectionIdx]
+	if w.doFullscreen || len(w.floating) == 0 {
+		return
+	}
+	w.floatFocused = !w.floatFocused
+	u.refreshRenderRect()
+	u.updateSelection()
+}
+
+// Popup runs a command in a floating pane that closes once the command exits
+func (u *Universe) Popup(command string) error {
+	u.wmOpMutex.Lock()
+	defer u.wmOpMutex.Unlock()
+
+	if command == "" {
+		return errors.New("no command given")
+	}
+	w := u.workspaces[u.selectionIdx]
+	if w.doFullscreen {
+		return errors.New("cannot open a popup while a pane is fullscreen")
+	}
+
+	p := w.newPane(u.renderer, PaneSpec{Command: command, Exec: true})
+	w.showFloat(&floatingPane{contents: p, rect: w.centeredRect(80), popup: true})
+
+	u.refreshRenderRect()
+	u.updateSelection()
+	return nil
+}
+
+// centeredRect returns a rect in the middle of the workspace taking up the
+// given percentage of its width and height
+func (s *workspace) centeredRect(percent int) Rect {
+	r := s.renderRect
+	w := r.W * percent / 100
+	h := r.H * percent / 100
+	return s.clampFloat(Rect{X: r.X + (r.W-w)/2, Y: r.Y + (r.H-h)/2, W: w, H: h})
+}
+
+// clampFloat keeps a floating pane within the workspace
+func (s *workspace) clampFloat(r Rect) Rect {
+	ws := s.renderRect
+	if r.W > ws.W {
+		r.W = ws.W
+	}
+	if r.H > ws.H {
+		r.H = ws.H
+	}
+	if r.W < minFloatW {
+		r.W = minFloatW
+	}
+	if r.H < minFloatH {
+		r.H = minFloatH
+	}
+	if r.X+r.W > ws.X+ws.W {
+		r.X = ws.X + ws.W - r.W
+	}
+	if r.Y+r.H > ws.Y+ws.H {
+		r.Y = ws.Y + ws.H - r.H
+	}
+	if r.X < ws.X {
+		r.X = ws.X
+	}
+	if r.Y < ws.Y {
+		r.Y = ws.Y
+	}
+	return r
+}
+
+// addFloat puts a node on top of the workspace's floating panes and selects it
+func (s *workspace) addFloat(n Node, r Rect) {
+	s.showFloat(&floatingPane{contents: n, rect: r})
+}
+
+func (s *workspace) showFloat(f *floatingPane) {
+	f.contents.SetDeathHandler(s.handleFloatDeath)
+	f.contents.SetHidden(s.renderer.hidden)
+	s.floating = append(s.floating, f)
+	s.floatFocused = true
+}
+
+func (s *workspace) removeFloat(f *floatingPane) {
+	for idx, x := range s.floating {
+		if x == f {
+			s.floating = append(s.floating[:idx], s.floating[idx+1:]...)
+			break
+		}
+	}
+	if len(s.floating) == 0 {
+		s.floatFocused = false
+	}
+}
+
+// raiseFloat moves a floating pane above all the others
+func (s *workspace) raiseFloat(f *floatingPane) {
+	s.removeFloat(f)
+	s.floating = append(s.floating, f)
+}
+
+// selectedFloat returns the floating pane that is selected, if any
+func (s *workspace) selectedFloat() *floatingPane {
+	if !s.floatFocused || len(s.floating) == 0 {
+		return nil
+	}
+	return s.floating[len(s.floating)-1]
+}
+
+// floatAt returns the topmost floating pane at the given coordinates
+func (s *workspace) floatAt(x, y int) *floatingPane {
+	for idx := len(s.floating) - 1; idx >= 0; idx-- {
+		if s.floating[idx].rect.contains(x, y) {
+			return s.floating[idx]
+		}
+	}
+	return nil
+}
+
+// tile adds a node to the tiled panes, next to the selected one
+func (s *workspace) tile(n Node) {
+	s.floatFocused = false
+	parent := s.contents.selectedParent()
+	parent.appendNode(n)
+	parent.selectionIdx = len(parent.elements) - 1
+}
+
+// retile replaces a workspace's tiled panes, once they are all gone, with its
+// topmost floating pane. It returns false if there was none, meaning that the
+// workspace is empty.
+func (s *workspace) retile() bool {
+	if len(s.floating) == 0 {
+		return false
+	}
+	f := s.floating[len(s.floating)-1]
+	s.removeFloat(f)
+	s.contents.Dead = false
+	s.contents.elements = nil
+	s.contents.selectionIdx = 0
+	s.tile(f.contents)
+	return true
+}
+
+func (s *workspace) handleFloatDeath(err error) {
+	if err != nil {
+		s.onDeath(err)
+		return
+	}
+
+	s.u.wmOpMutex.Lock()
+	defer s.u.wmOpMutex.Unlock()
+
+	s.u.pruneHistory()
+	removed := false
+	for idx := len(s.floating) - 1; idx >= 0; idx-- {
+		if s.floating[idx].contents.IsDead() {
+			s.removeFloat(s.floating[idx])
+			removed = true
+		}
+	}
+	if removed && !s.renderer.hidden {
+		s.u.refreshRenderRect()
+		s.u.updateSelection()
+	}
+}
+
+// refreshCovered tells everything beneath each floating pane not to draw over
+// it. A fullscreen pane covers the floating panes instead.
+func (s *workspace) refreshCovered() {
+	rects := []Rect{}
+	for _, f := range s.floating {
+		f.rect = s.clampFloat(f.rect)
+		rects = append(rects, f.rect)
+	}
+	if s.doFullscreen {
+		rects = nil
+	}
+
+	s.renderer.covered = rects
+	s.contents.SetCovered(rects)
+	for idx, f := range s.floating {
+		f.contents.SetHidden(s.renderer.hidden || s.doFullscreen)
+		if idx+1 < len(rects) {
+			f.contents.SetCovered(rects[idx+1:])
+		} else {
+			f.contents.SetCovered(nil)
+		}
+	}
+}
+
+// refreshFloats lays out the floating panes above the tiled ones
+func (s *workspace) refreshFloats() {
+	for _, f := range s.floating {
+		r := f.inner()
+		f.contents.SetRenderRect(false, r.X, r.Y, r.W, r.H)
+	}
+	s.drawFloatBorders()
+}
+
+// drawFloatBorders draws a frame around each floating pane, bottom to top
+func (s *workspace) drawFloatBorders() {
+	if s.renderer.hidden || s.doFullscreen {
+		return
+	}
+
+	theme := s.u.settings.Theme
+	chars := theme.Chars
+	selected := s.selectedFloat()
+	for idx, f := range s.floating {
+		above := []Rect{}
+		for _, g := range s.floating[idx+1:] {
+			above = append(above, g.rect)
+		}
+
+		style := theme.Border
+		if f == selected {
+			style = theme.ActiveBorder
+		} else if f.contents == s.u.marked {
+			style = theme.MarkedBorder
+		} else if s.isSynced(f.contents) {
+			style = theme.SyncBorder
+		}
+		draw := func(x, y int, r rune) {
+			if !covered(above, x, y) {
+				s.renderer.Renderer.HandleCh(ecma48.PositionedChar{
+					Rune:   r,
+					Cursor: ecma48.Cursor{X: x, Y: y, Style: style},
+				})
+			}
+		}
+
+		r := f.rect
+		title := []rune(" " + f.contents.Title() + " ")
+		for i := 1; i < r.W-1; i++ {
+			ch := chars.Horizontal
+			if i >= 2 && i-2 < len(title) && i < r.W-2 {
+				ch = title[i-2]
+			}
+			draw(r.X+i, r.Y, ch)
+			draw(r.X+i, r.Y+r.H-1, chars.Horizontal)
+		}
+		for i := 1; i < r.H-1; i++ {
+			draw(r.X, r.Y+i, chars.Vertical)
+			draw(r.X+r.W-1, r.Y+i, chars.Vertical)
+		}
+		draw(r.X, r.Y, chars.TopLeft)
+		draw(r.X+r.W-1, r.Y, chars.TopRight)
+		draw(r.X, r.Y+r.H-1, chars.BottomLeft)
+		draw(r.X+r.W-1, r.Y+r.H-1, chars.BottomRight)
+	}
+}
+
+// moveFloat moves the selected floating pane in the given direction
+func (s *workspace) moveFloat(f *floatingPane, d Direction) {
+	switch d {
+	case Up:
+		f.rect.Y -= floatStep / 2
+	case Down:
+		f.rect.Y += floatStep / 2
+	case Left:
+		f.rect.X -= floatStep
+	case Right:
+		f.rect.X += floatStep
+	}
+}
+
+// resizeFloat grows or shrinks the selected floating pane's right or bottom
+// edge, like resizing a tiled pane moves its divider
+func (s *workspace) resizeFloat(f *floatingPane, d Direction) {
+	switch d {
+	case Up:
+		f.rect.H -= floatStep / 2
+	case Down:
+		f.rect.H += floatStep / 2
+	case Left:
+		f.rect.W -= floatStep
+	case Right:
+		f.rect.W += floatStep
+	}
+}
+
+// dragFloat moves a floating pane when dragged by its top edge, or resizes it
+// when dragged by its right or bottom edge. It returns false if the drag
+// didn't start on a floating pane.
+func (s *workspace) dragFloat(x1, y1, x2, y2 int) bool {
+	f := s.floatAt(x1, y1)
+	if f == nil {
+		return false
+	}
+
+	r := f.rect
+	switch {
+	case y1 == r.Y:
+		f.rect.X += x2 - x1
+		f.rect.Y += y2 - y1
+	case x1 == r.X+r.W-1 || y1 == r.Y+r.H-1:
+		if x1 == r.X+r.W-1 {
+			f.rect.W += x2 - x1
+		}
+		if y1 == r.Y+r.H-1 {
+			f.rect.H += y2 - y1
+		}
+	}
+	return true
+}
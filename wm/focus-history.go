@@ -0,0 +1,140 @@
+package wm
+
+import (
+	"errors"
+)
+
+// historyLen is how many focused panes are remembered
+const historyLen = 100
+
+// FocusLastPane selects the pane that was selected before the current one,
+// wherever it is. Repeating it bounces between the two.
+func (u *Universe) FocusLastPane() error {
+	u.wmOpMutex.Lock()
+	defer u.wmOpMutex.Unlock()
+
+	u.pruneHistory()
+	current := u.getSelectedNode()
+	for idx := u.historyPos - 1; idx >= 0; idx-- {
+		n := u.history[idx]
+		if n != current && u.focusable(n) {
+			return u.focusNode(n)
+		}
+	}
+	return errors.New("no other pane in focus history")
+}
+
+// FocusBack selects the previous pane in the focus history, like the back
+// button of a browser
+func (u *Universe) FocusBack() error {
+	return u.stepHistory(-1)
+}
+
+// FocusForward undoes FocusBack
+func (u *Universe) FocusForward() error {
+	return u.stepHistory(1)
+}
+
+func (u *Universe) stepHistory(step int) error {
+	u.wmOpMutex.Lock()
+	defer u.wmOpMutex.Unlock()
+
+	u.pruneHistory()
+	for pos := u.historyPos + step; 0 <= pos && pos < len(u.history); pos += step {
+		if u.focusable(u.history[pos]) {
+			u.historyPos = pos
+			return u.focusNode(u.history[pos])
+		}
+	}
+	return errors.New("no more panes in focus history")
+}
+
+// recordFocus adds the selected pane to the focus history, dropping anything
+// ahead of the current position
+func (u *Universe) recordFocus() {
+	u.pruneHistory()
+
+	n := u.getSelectedNode()
+	if _, ok := n.(*split); ok {
+		return
+	}
+	if u.historyPos < len(u.history) && u.history[u.historyPos] == n {
+		return
+	}
+
+	if len(u.history) > 0 {
+		u.history = u.history[:u.historyPos+1]
+	}
+	u.history = append(u.history, n)
+	if len(u.history) > historyLen {
+		u.history = u.history[len(u.history)-historyLen:]
+	}
+	u.historyPos = len(u.history) - 1
+}
+
+// pruneHistory forgets dead panes, along with repeats left behind by them
+func (u *Universe) pruneHistory() {
+	pruned := []Node{}
+	pos := 0
+	for idx, n := range u.history {
+		dup := len(pruned) > 0 && pruned[len(pruned)-1] == n
+		if !n.IsDead() && !dup {
+			pruned = append(pruned, n)
+		}
+		if idx == u.historyPos {
+			pos = len(pruned) - 1
+		}
+	}
+	if pos < 0 {
+		pos = 0
+	}
+	u.history = pruned
+	u.historyPos = pos
+}
+
+// focusable returns whether a pane can be selected, which excludes panes
+// hidden in the scratchpad
+func (u *Universe) focusable(n Node) bool {
+	if n.IsDead() {
+		return false
+	}
+	slot, ok := u.findSlot(n)
+	return ok && (slot.split != nil || slot.w != nil)
+}
+
+// focusNode selects a pane, showing its workspace if needed
+func (u *Universe) focusNode(n Node) error {
+	if u.workspaces[u.selectionIdx].doFullscreen {
+		return errors.New("cannot change focus while a pane is fullscreen")
+	}
+
+	for idx, w := range u.workspaces {
+		found := false
+		if w.contents.selectNode(n) {
+			w.contents.clearSelectedWhole()
+			w.floatFocused = false
+			found = true
+		}
+		for _, f := range w.floating {
+			if f.contents == n {
+				w.raiseFloat(f)
+				w.floatFocused = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			continue
+		}
+
+		if idx != u.selectionIdx {
+			// showWorkspace refreshes everything itself
+			u.showWorkspace(idx)
+		} else {
+			u.refreshRenderRect()
+			u.updateSelection()
+		}
+		return nil
+	}
+	return errors.New("pane not found")
+}
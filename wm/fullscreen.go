@@ -10,25 +10,34 @@ func (u *Universe) ToggleFullscreen() {
 }
 
 func (s *workspace) toggleFullscreen() {
+	if s.floatFocused {
+		return // floating panes can't be fullscreen
+	}
 	s.setFullscreen(!s.doFullscreen)
 }
 
 func (s *workspace) setFullscreen(fullscreen bool) {
 	s.doFullscreen = fullscreen
+	s.refreshCovered()
 	s.contents.setFullscreen(
 		fullscreen,
-		s.contents.GetRenderRect().W,
-		s.contents.GetRenderRect().H,
+		s.renderRect.W,
+		s.renderRect.H,
 	)
 	if !fullscreen {
 		s.contents.refreshRenderRect(false)
+		s.refreshFloats()
 	}
 }
 
 func (s *split) setFullscreen(fullscreen bool, w, h int) {
 	for idx, n := range s.elements {
 		thisOne := fullscreen && idx == s.selectionIdx
-		switch child := n.contents.(type) {
+		child := n.contents
+		if idx == s.selectionIdx {
+			child = s.selectedChild()
+		}
+		switch child := child.(type) {
 		case Container:
 			child.setFullscreen(thisOne, w, h)
 		case Node:
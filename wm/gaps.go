@@ -0,0 +1,51 @@
+package wm
+
+import (
+	"github.com/aaronjanse/3mux/ecma48"
+)
+
+// dividerWidth is how many cells separate the split's children: a single
+// divider line, or the inner gap if there is one
+func (s *split) dividerWidth() int {
+	if s.u.settings.InnerGap > 0 {
+		return s.u.settings.InnerGap
+	}
+	return 1
+}
+
+// tiledRect is the area of the workspace left for its tiled panes
+func (s *workspace) tiledRect() Rect {
+	r := s.renderRect
+	if g := s.u.settings.OuterGap; g > 0 && r.W > 2*g && r.H > 2*g {
+		r = Rect{X: r.X + g, Y: r.Y + g, W: r.W - 2*g, H: r.H - 2*g}
+	}
+	if s.titleRow() {
+		r.Y++
+		r.H--
+	}
+	return r
+}
+
+// clearOuterGap blanks the area around the tiled panes, which would otherwise
+// keep whatever was last drawn there
+func (s *workspace) clearOuterGap() {
+	if s.u.settings.OuterGap <= 0 || s.doFullscreen {
+		return
+	}
+	inner := s.tiledRect()
+	if s.titleRow() {
+		inner.Y--
+		inner.H++
+	}
+	r := s.renderRect
+	for y := r.Y; y < r.Y+r.H; y++ {
+		for x := r.X; x < r.X+r.W; x++ {
+			if !inner.contains(x, y) {
+				s.renderer.HandleCh(ecma48.PositionedChar{
+					Rune:   ' ',
+					Cursor: ecma48.Cursor{X: x, Y: y},
+				})
+			}
+		}
+	}
+}
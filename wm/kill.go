@@ -3,6 +3,12 @@ package wm
 func (u *Universe) Kill() {
 	for _, n := range u.workspaces {
 		n.contents.Kill()
+		for _, f := range n.floating {
+			f.contents.Kill()
+		}
+	}
+	for _, f := range u.scratchpad {
+		f.contents.Kill()
 	}
 }
 
@@ -14,22 +20,41 @@ func (s *split) Kill() {
 }
 
 func (u *Universe) notifyDeath() {
+	if len(u.workspaces) == 0 {
+		return
+	}
 	u.workspaces[u.selectionIdx].notifyDeath()
 }
 
 func (s *workspace) notifyDeath() {
-	if s.doFullscreen && s.getSelectedNode().IsDead() {
+	if s.doFullscreen && s.selectedTarget().IsDead() {
 		s.setFullscreen(false)
 	}
 }
 
 func (u *Universe) handleChildDeath(err error) {
 	u.dead = true
-	u.onDeath(err) // FIXME: only supports one workspace
+	u.onDeath(err)
 }
 
+// handleChildDeath is called once every pane in the workspace has exited, or
+// upon an error
 func (s *workspace) handleChildDeath(err error) {
-	s.onDeath(err)
+	if err != nil {
+		s.onDeath(err)
+		return
+	}
+
+	s.u.wmOpMutex.Lock()
+	defer s.u.wmOpMutex.Unlock()
+	if s.retile() {
+		if !s.renderer.hidden {
+			s.u.refreshRenderRect()
+			s.u.updateSelection()
+		}
+		return
+	}
+	s.u.removeWorkspace(s)
 }
 
 func (s *split) handleChildDeath(err error) {
@@ -42,7 +67,9 @@ func (s *split) handleChildDeath(err error) {
 	if len(s.elements) == 0 || err != nil {
 		s.Dead = true
 		s.onDeath(err)
-	} else {
+	} else if !s.Dead {
+		// a container killed as a whole has already been removed, so it must
+		// not draw over whatever took its place
 		s.refreshRenderRect(false)
 		s.elements[s.selectionIdx].contents.UpdateSelection(s.selected)
 	}
@@ -0,0 +1,129 @@
+package wm
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// layoutPresets are the arrangements that cycle-layout steps through, named
+// after those of tmux's select-layout
+var layoutPresets = []string{
+	"even-horizontal",
+	"even-vertical",
+	"main-vertical",
+	"main-horizontal",
+	"tiled",
+}
+
+func init() {
+	for _, name := range layoutPresets {
+		preset := name
+		FuncNames["select-layout-"+preset] = func(u *Universe) error {
+			return u.SelectLayout(preset)
+		}
+	}
+}
+
+// SelectLayout rearranges the tiled panes of the current workspace into one
+// of the layoutPresets. The panes themselves and the selection are kept.
+func (u *Universe) SelectLayout(preset string) error {
+	u.wmOpMutex.Lock()
+	defer u.wmOpMutex.Unlock()
+
+	return u.selectLayout(preset)
+}
+
+// CycleLayout applies the preset after the one applied last
+func (u *Universe) CycleLayout() error {
+	u.wmOpMutex.Lock()
+	defer u.wmOpMutex.Unlock()
+
+	next := 0
+	for idx, name := range layoutPresets {
+		if name == u.workspaces[u.selectionIdx].preset {
+			next = (idx + 1) % len(layoutPresets)
+		}
+	}
+	return u.selectLayout(layoutPresets[next])
+}
+
+func (u *Universe) selectLayout(preset string) error {
+	w := u.workspaces[u.selectionIdx]
+	if w.doFullscreen {
+		return errors.New("cannot change layout while a pane is fullscreen")
+	}
+
+	err := w.applyPreset(preset, u.settings.MainPaneRatio)
+	if err != nil {
+		return err
+	}
+
+	u.simplify()
+	u.refreshRenderRect()
+	u.updateSelection()
+	return nil
+}
+
+func (s *workspace) applyPreset(preset string, mainRatio float32) error {
+	root := s.contents
+	panes := root.leaves()
+	selected := root.getSelectedNode()
+	root.clearSelectedWhole()
+
+	// build nests splits within the root, which is reused so that the
+	// workspace's death handler stays in place
+	build := func(vertical bool, nodes []Node) Node {
+		if len(nodes) == 1 {
+			return nodes[0]
+		}
+		return newSplit(s.renderer, s.u, root.handleChildDeath, s.renderRect, vertical, 0, nodes, s.newPane)
+	}
+
+	var vertical bool
+	var children []Node
+	var sizes []float32
+
+	switch preset {
+	case "even-horizontal", "even-vertical":
+		vertical = preset == "even-vertical"
+		children = panes
+	case "main-vertical", "main-horizontal":
+		// main-vertical puts the main pane on the left with the others
+		// stacked beside it, like in tmux
+		vertical = preset == "main-horizontal"
+		children = []Node{panes[0]}
+		if len(panes) > 1 {
+			children = append(children, build(!vertical, panes[1:]))
+			sizes = []float32{mainRatio, 1 - mainRatio}
+		}
+	case "tiled":
+		vertical = true
+		cols := int(math.Ceil(math.Sqrt(float64(len(panes)))))
+		for start := 0; start < len(panes); start += cols {
+			end := start + cols
+			if end > len(panes) {
+				end = len(panes)
+			}
+			children = append(children, build(false, panes[start:end]))
+		}
+	default:
+		return fmt.Errorf("unknown layout %q", preset)
+	}
+
+	root.verticallyStacked = vertical
+	root.mode = modeSplit
+	root.elements = nil
+	for idx, child := range children {
+		size := 1 / float32(len(children))
+		if sizes != nil {
+			size = sizes[idx]
+		}
+		child.SetDeathHandler(root.handleChildDeath)
+		root.elements = append(root.elements, SizedNode{size: size, contents: child})
+	}
+	root.selectNode(selected)
+
+	s.preset = preset
+	return nil
+}
@@ -0,0 +1,372 @@
+package wm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/aaronjanse/3mux/ecma48"
+)
+
+/*
+A Layout describes a Universe in a form that can be saved to a file and used
+to build an identical Universe later:
+
+	{
+	  "version": 1,
+	  "selected": 0,
+	  "workspaces": [
+	    {
+	      "split": "horizontal",
+	      "selected": 1,
+	      "children": [
+	        {"size": 0.6, "pane": {"cwd": "/home/me/src", "command": "vim"}},
+	        {"size": 0.4, "split": "vertical", "children": [
+	          {"size": 0.5, "pane": {"cwd": "/home/me/src"}},
+	          {"size": 0.5, "pane": {"command": "make watch"}}
+	        ]}
+	      ]
+	    }
+	  ]
+	}
+
+A node is either a pane or a split. Horizontal splits place their children
+side by side and vertical splits stack them top to bottom. Sizes are relative
+to their siblings; if every size is omitted, the children share the space
+evenly. A split with "mode" set to "tabbed" or "stacking" shows only its
+selected child, under a row of titles. Selection indices are optional and
+default to the first child.
+Top-level nodes may set "workspace" to the number of their workspace, and
+"floating" to the nodes floating above it. Each floating node also has a
+"rect", which includes its border, and "scratchpad" if it belongs there. The
+top-level "scratchpad" lists the nodes hidden in the scratchpad, next to be
+shown first.
+*/
+
+// splitModes maps the modes of a LayoutNode to those of a split
+var splitModes = map[string]splitMode{
+	"":         modeSplit,
+	"tabbed":   modeTabbed,
+	"stacking": modeStacking,
+}
+
+// LayoutVersion is the version of the layout format this build reads and writes
+const LayoutVersion = 1
+
+// A Layout describes every workspace in a Universe
+type Layout struct {
+	Version    int              `json:"version"`
+	Selected   int              `json:"selected,omitempty"`
+	Workspaces []LayoutNode     `json:"workspaces"`
+	Scratchpad []FloatingLayout `json:"scratchpad,omitempty"`
+}
+
+// A LayoutNode is either a pane or a split
+type LayoutNode struct {
+	// Workspace is the number of the workspace a top-level node describes. It
+	// defaults to the node's position in the list, starting at 1.
+	Workspace int `json:"workspace,omitempty"`
+
+	Size float32 `json:"size,omitempty"`
+
+	// Split is "horizontal" or "vertical"
+	Split string `json:"split,omitempty"`
+	// Mode is "tabbed" or "stacking" for splits that show one child at a time
+	Mode     string       `json:"mode,omitempty"`
+	Selected int          `json:"selected,omitempty"`
+	Children []LayoutNode `json:"children,omitempty"`
+
+	Pane *PaneSpec `json:"pane,omitempty"`
+
+	// Floating is the nodes floating above a top-level node's workspace, from
+	// the bottom up
+	Floating []FloatingLayout `json:"floating,omitempty"`
+}
+
+// A FloatingLayout is a node floating above a workspace or hidden in the
+// scratchpad
+type FloatingLayout struct {
+	// Rect includes the border drawn around the node. It is moved and
+	// shrunk to fit on the screen.
+	Rect Rect `json:"rect"`
+	// Scratchpad is set for floating nodes that belong in the scratchpad
+	Scratchpad bool `json:"scratchpad,omitempty"`
+	LayoutNode
+}
+
+// A PaneSpec describes what should run in a pane
+type PaneSpec struct {
+	// Cwd is the working directory of the pane's shell
+	Cwd string `json:"cwd,omitempty"`
+	// Command is typed into the pane's shell once it starts
+	Command string `json:"command,omitempty"`
+	// History is displayed before anything the shell prints. It holds text
+	// as captured by capture-pane -e.
+	History string `json:"history,omitempty"`
+	// Exec runs Command in place of a shell, so that the pane closes once
+	// the command exits. It is used for popups, which layouts don't save.
+	Exec bool `json:"-"`
+}
+
+// ParseLayout reads and validates a layout
+func ParseLayout(data []byte) (*Layout, error) {
+	var l Layout
+	err := json.Unmarshal(data, &l)
+	if err != nil {
+		return nil, fmt.Errorf("malformed layout: %s", err)
+	}
+
+	if l.Version != LayoutVersion {
+		return nil, fmt.Errorf("unsupported layout version %d (expected %d)", l.Version, LayoutVersion)
+	}
+	if len(l.Workspaces) == 0 {
+		return nil, errors.New("layout has no workspaces")
+	}
+	if l.Selected < 0 || l.Selected >= len(l.Workspaces) {
+		return nil, fmt.Errorf("selected workspace %d does not exist", l.Selected)
+	}
+	lastNum := 0
+	for idx, w := range l.Workspaces {
+		num := workspaceNum(idx, w)
+		if num <= lastNum {
+			return nil, fmt.Errorf("workspace %d is out of order", num)
+		}
+		lastNum = num
+
+		err := w.validate()
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range w.Floating {
+			err := f.validate()
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, f := range l.Scratchpad {
+		err := f.validate()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &l, nil
+}
+
+// workspaceNum returns the number of the workspace at the given position in a
+// layout
+func workspaceNum(idx int, n LayoutNode) int {
+	if n.Workspace != 0 {
+		return n.Workspace
+	}
+	return idx + 1
+}
+
+func (f *FloatingLayout) validate() error {
+	if f.Rect.W < minFloatW || f.Rect.H < minFloatH {
+		return fmt.Errorf("floating rect must be at least %dx%d", minFloatW, minFloatH)
+	}
+	if len(f.Floating) > 0 {
+		return errors.New("only workspaces can have floating nodes")
+	}
+	return f.LayoutNode.validate()
+}
+
+func (n *LayoutNode) validate() error {
+	if n.Pane != nil {
+		if n.Split != "" || n.Mode != "" || len(n.Children) > 0 {
+			return errors.New("layout node cannot be both a pane and a split")
+		}
+		return nil
+	}
+
+	if n.Split != "horizontal" && n.Split != "vertical" {
+		return fmt.Errorf("invalid split %q (expected \"horizontal\" or \"vertical\")", n.Split)
+	}
+	if _, ok := splitModes[n.Mode]; !ok {
+		return fmt.Errorf("invalid mode %q (expected \"tabbed\" or \"stacking\")", n.Mode)
+	}
+	if len(n.Children) == 0 {
+		return errors.New("layout split has no children")
+	}
+	if n.Selected < 0 || n.Selected >= len(n.Children) {
+		return fmt.Errorf("selected child %d does not exist", n.Selected)
+	}
+
+	sized := 0
+	for _, c := range n.Children {
+		if c.Size < 0 {
+			return fmt.Errorf("invalid size %f", c.Size)
+		}
+		if c.Size > 0 {
+			sized++
+		}
+		if len(c.Floating) > 0 {
+			return errors.New("only workspaces can have floating nodes")
+		}
+		err := c.validate()
+		if err != nil {
+			return err
+		}
+	}
+	if sized != 0 && sized != len(n.Children) {
+		return errors.New("layout split must give a size to all of its children or none")
+	}
+
+	return nil
+}
+
+// Layout describes the Universe's current state. If history is true, each
+// pane's scrollback and screen are included.
+func (u *Universe) Layout(history bool) Layout {
+	u.wmOpMutex.Lock()
+	defer u.wmOpMutex.Unlock()
+
+	l := Layout{
+		Version:  LayoutVersion,
+		Selected: u.selectionIdx,
+	}
+	for _, w := range u.workspaces {
+		n := w.contents.layout(history)
+		n.Workspace = w.num
+		for _, f := range w.floating {
+			if !f.popup {
+				n.Floating = append(n.Floating, f.layout(history))
+			}
+		}
+		l.Workspaces = append(l.Workspaces, n)
+	}
+	for _, f := range u.scratchpad {
+		l.Scratchpad = append(l.Scratchpad, f.layout(history))
+	}
+	return l
+}
+
+func (f *floatingPane) layout(history bool) FloatingLayout {
+	return FloatingLayout{
+		Rect:       f.rect,
+		Scratchpad: f.scratchpad,
+		LayoutNode: nodeLayout(f.contents, history),
+	}
+}
+
+// nodeLayout describes a pane or a container
+func nodeLayout(n Node, history bool) LayoutNode {
+	switch x := n.(type) {
+	case Container:
+		return x.layout(history)
+	default:
+		spec := x.Spec(history)
+		return LayoutNode{Pane: &spec}
+	}
+}
+
+func (s *split) layout(history bool) LayoutNode {
+	out := LayoutNode{
+		Split:    "horizontal",
+		Selected: s.selectionIdx,
+	}
+	if s.verticallyStacked {
+		out.Split = "vertical"
+	}
+	for name, mode := range splitModes {
+		if mode == s.mode {
+			out.Mode = name
+		}
+	}
+
+	for _, e := range s.elements {
+		child := nodeLayout(e.contents, history)
+		child.Size = e.size
+		out.Children = append(out.Children, child)
+	}
+	return out
+}
+
+// NewUniverseFromLayout is like NewUniverse but builds its workspaces from a
+// layout returned by ParseLayout
+func NewUniverseFromLayout(renderer ecma48.Renderer, helpBar bool, enableStatusBar bool, settings Settings, onDeath func(error), renderRect Rect, newPane NewPaneFunc, l *Layout) *Universe {
+	u := &Universe{
+		selectionIdx:    l.Selected,
+		renderRect:      renderRect,
+		onDeath:         onDeath,
+		renderer:        renderer,
+		helpBar:         helpBar,
+		enableStatusBar: enableStatusBar,
+		settings:        settings,
+		wmOpMutex:       &sync.Mutex{},
+	}
+	newPane = u.wrapNewPane(newPane)
+	u.newPane = newPane
+	for idx, n := range l.Workspaces {
+		num := workspaceNum(idx, n)
+		floats := n.Floating
+		if n.Pane != nil {
+			// workspaces always hold a split
+			n = LayoutNode{Split: "horizontal", Children: []LayoutNode{n}}
+		}
+		w := buildWorkspace(num, renderer, u, u.handleChildDeath, u.workspaceRect(), newPane, func(w *workspace) *split {
+			return splitFromLayout(w.renderer, u, w.handleChildDeath, w.renderRect, n, newPane)
+		})
+		for _, fl := range floats {
+			f := &floatingPane{
+				contents:   nodeFromLayout(w.renderer, u, w.handleFloatDeath, fl.Rect, fl.LayoutNode, newPane),
+				rect:       w.clampFloat(fl.Rect),
+				scratchpad: fl.Scratchpad,
+			}
+			w.showFloat(f)
+		}
+		// the tiled panes keep the selection, as when a workspace is shown
+		w.floatFocused = false
+		w.setHidden(idx != u.selectionIdx)
+		u.workspaces = append(u.workspaces, w)
+	}
+	for _, fl := range l.Scratchpad {
+		w := u.workspaces[u.selectionIdx]
+		f := &floatingPane{
+			contents:   nodeFromLayout(w.renderer, u, u.handleScratchpadDeath, fl.Rect, fl.LayoutNode, newPane),
+			rect:       w.clampFloat(fl.Rect),
+			scratchpad: true,
+		}
+		u.hideInScratchpad(f)
+		// the pane only starts reading its program's output once it is
+		// given a size
+		in := f.inner()
+		f.contents.SetRenderRect(false, in.X, in.Y, in.W, in.H)
+	}
+	u.updateSelection()
+	u.refreshRenderRect()
+	return u
+}
+
+// nodeFromLayout builds a pane or a split from a layout node
+func nodeFromLayout(renderer ecma48.Renderer, u *Universe, onDeath func(error), rect Rect, n LayoutNode, newPane NewPaneFunc) Node {
+	if n.Pane != nil {
+		return newPane(u.renderer, *n.Pane)
+	}
+	return splitFromLayout(renderer, u, onDeath, rect, n, newPane)
+}
+
+func splitFromLayout(renderer ecma48.Renderer, u *Universe, onDeath func(error), rect Rect, n LayoutNode, newPane NewPaneFunc) *split {
+	s := newSplit(renderer, u, onDeath, rect, n.Split == "vertical", n.Selected, []Node{}, newPane)
+	s.mode = splitModes[n.Mode]
+
+	var total float32
+	for _, c := range n.Children {
+		total += c.Size
+	}
+
+	for _, c := range n.Children {
+		child := nodeFromLayout(renderer, u, s.handleChildDeath, rect, c, newPane)
+		child.SetDeathHandler(s.handleChildDeath)
+
+		size := 1 / float32(len(n.Children))
+		if total > 0 {
+			size = c.Size / total
+		}
+		s.elements = append(s.elements, SizedNode{size: size, contents: child})
+	}
+	return s
+}
@@ -0,0 +1,140 @@
+package wm
+
+import (
+	"testing"
+)
+
+func TestParseLayout(t *testing.T) {
+	tests := []struct {
+		name   string
+		layout string
+		err    bool
+	}{
+		{
+			name:   "single pane",
+			layout: `{"version": 1, "workspaces": [{"pane": {}}]}`,
+		},
+		{
+			name: "nested splits",
+			layout: `{"version": 1, "selected": 1, "workspaces": [
+				{"split": "horizontal", "selected": 1, "children": [
+					{"size": 0.6, "pane": {"cwd": "/tmp", "command": "vim"}},
+					{"size": 0.4, "split": "vertical", "children": [
+						{"pane": {}}, {"pane": {}}
+					]}
+				]},
+				{"workspace": 3, "split": "vertical", "mode": "tabbed", "children": [{"pane": {}}]}
+			]}`,
+		},
+		{
+			name:   "malformed",
+			layout: `{"version": 1, "workspaces": [`,
+			err:    true,
+		},
+		{
+			name:   "wrong version",
+			layout: `{"version": 2, "workspaces": [{"pane": {}}]}`,
+			err:    true,
+		},
+		{
+			name:   "no workspaces",
+			layout: `{"version": 1, "workspaces": []}`,
+			err:    true,
+		},
+		{
+			name:   "selected workspace out of range",
+			layout: `{"version": 1, "selected": 1, "workspaces": [{"pane": {}}]}`,
+			err:    true,
+		},
+		{
+			name:   "workspaces out of order",
+			layout: `{"version": 1, "workspaces": [{"workspace": 2, "pane": {}}, {"workspace": 2, "pane": {}}]}`,
+			err:    true,
+		},
+		{
+			name:   "pane and split",
+			layout: `{"version": 1, "workspaces": [{"split": "vertical", "pane": {}}]}`,
+			err:    true,
+		},
+		{
+			name:   "bad split",
+			layout: `{"version": 1, "workspaces": [{"split": "diagonal", "children": [{"pane": {}}]}]}`,
+			err:    true,
+		},
+		{
+			name:   "bad mode",
+			layout: `{"version": 1, "workspaces": [{"split": "vertical", "mode": "grid", "children": [{"pane": {}}]}]}`,
+			err:    true,
+		},
+		{
+			name:   "empty split",
+			layout: `{"version": 1, "workspaces": [{"split": "vertical"}]}`,
+			err:    true,
+		},
+		{
+			name:   "selected child out of range",
+			layout: `{"version": 1, "workspaces": [{"split": "vertical", "selected": 1, "children": [{"pane": {}}]}]}`,
+			err:    true,
+		},
+		{
+			name:   "negative size",
+			layout: `{"version": 1, "workspaces": [{"split": "vertical", "children": [{"size": -1, "pane": {}}]}]}`,
+			err:    true,
+		},
+		{
+			name:   "some sizes missing",
+			layout: `{"version": 1, "workspaces": [{"split": "vertical", "children": [{"size": 1, "pane": {}}, {"pane": {}}]}]}`,
+			err:    true,
+		},
+		{
+			name:   "invalid child",
+			layout: `{"version": 1, "workspaces": [{"split": "vertical", "children": [{"split": "vertical"}]}]}`,
+			err:    true,
+		},
+		{
+			name: "floating and scratchpad",
+			layout: `{"version": 1, "workspaces": [
+				{"pane": {}, "floating": [
+					{"rect": {"x": 5, "y": 2, "w": 40, "h": 10}, "pane": {"command": "htop"}},
+					{"rect": {"x": 0, "y": 0, "w": 20, "h": 8}, "scratchpad": true, "split": "vertical", "children": [{"pane": {}}]}
+				]}
+			], "scratchpad": [{"rect": {"x": 0, "y": 0, "w": 30, "h": 6}, "pane": {}}]}`,
+		},
+		{
+			name:   "floating rect too small",
+			layout: `{"version": 1, "workspaces": [{"pane": {}, "floating": [{"rect": {"w": 2, "h": 2}, "pane": {}}]}]}`,
+			err:    true,
+		},
+		{
+			name:   "invalid floating node",
+			layout: `{"version": 1, "workspaces": [{"pane": {}, "floating": [{"rect": {"w": 20, "h": 8}, "split": "vertical"}]}]}`,
+			err:    true,
+		},
+		{
+			name:   "floating below the top level",
+			layout: `{"version": 1, "workspaces": [{"split": "vertical", "children": [{"pane": {}, "floating": [{"rect": {"w": 20, "h": 8}, "pane": {}}]}]}]}`,
+			err:    true,
+		},
+		{
+			name:   "invalid scratchpad node",
+			layout: `{"version": 1, "workspaces": [{"pane": {}}], "scratchpad": [{"rect": {"w": 20, "h": 8}}]}`,
+			err:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		_, err := ParseLayout([]byte(tt.layout))
+		if (err != nil) != tt.err {
+			t.Errorf("%s: ParseLayout returned %v, want error: %v", tt.name, err, tt.err)
+		}
+	}
+}
+
+func TestWorkspaceNum(t *testing.T) {
+	if n := workspaceNum(0, LayoutNode{}); n != 1 {
+		t.Errorf("workspaceNum(0) = %d, want 1", n)
+	}
+	if n := workspaceNum(0, LayoutNode{Workspace: 4}); n != 4 {
+		t.Errorf("workspaceNum(0) with workspace 4 = %d, want 4", n)
+	}
+}
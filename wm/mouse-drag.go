@@ -0,0 +1,347 @@
+package wm
+
+import (
+	"fmt"
+
+	"github.com/aaronjanse/3mux/ecma48"
+)
+
+// A dragState is what a mouse press picked up to be dragged
+type dragState struct {
+	workspace *workspace
+	// startX and startY are where the mouse was pressed
+	startX, startY int
+	moved          bool
+
+	// dividers are the borders between tiled panes being dragged. There are
+	// two of them when the mouse was pressed on a T-junction.
+	dividers []divider
+
+	// pane is the tiled pane being dragged by its title to be dropped
+	// elsewhere, and drop is where it would land
+	pane Node
+	drop dropZone
+
+	// float is the floating pane being moved by its top edge or resized by
+	// its others, and floatRect is where it was when the drag began
+	float     *floatingPane
+	floatRect Rect
+	moveFloat bool
+}
+
+// A divider is the border between a split's children idx and idx+1
+type divider struct {
+	s   *split
+	idx int
+}
+
+// BeginDrag picks what a mouse press at the given coordinates will drag: the
+// edge of a floating pane, a tiled pane by its title, or one or two dividers
+// between tiled panes. With grab set, as it is while Alt is held, the pane
+// under the pointer is dragged from anywhere inside it.
+func (u *Universe) BeginDrag(x, y int, grab bool) {
+	u.wmOpMutex.Lock()
+	defer u.wmOpMutex.Unlock()
+
+	u.drag = nil
+	w := u.workspaces[u.selectionIdx]
+	if w.doFullscreen {
+		return
+	}
+
+	d := &dragState{workspace: w, startX: x, startY: y}
+	if f := w.floatAt(x, y); f != nil {
+		// a float is never dragged by its inside, nor is anything below it
+		r := f.rect
+		switch {
+		case grab, y == r.Y:
+			d.moveFloat = true
+		case x == r.X+r.W-1 || y == r.Y+r.H-1:
+		default:
+			return
+		}
+		d.float = f
+		d.floatRect = r
+		u.drag = d
+		return
+	}
+
+	if grab {
+		d.pane = w.contents.leafAt(x, y)
+		if d.pane != nil {
+			u.drag = d
+		}
+		return
+	}
+
+	// the border above a pane may also be a divider, so grabbing the pane
+	// takes its title there
+	d.dividers = w.dividersAt(x, y)
+	if n, onTitle := w.grabAt(x, y); n != nil && (onTitle || len(d.dividers) == 0) {
+		d.dividers = nil
+		d.pane = n
+	}
+	if d.pane != nil || len(d.dividers) > 0 {
+		u.drag = d
+	}
+}
+
+// DragTo moves whatever is being dragged to follow the mouse
+func (u *Universe) DragTo(x, y int) {
+	u.wmOpMutex.Lock()
+	defer u.wmOpMutex.Unlock()
+
+	if !u.dragValid() {
+		u.drag = nil
+		return
+	}
+	u.drag.moved = true
+	if u.drag.pane != nil {
+		u.previewDrop(x, y)
+		return
+	}
+	u.dragTo(x, y)
+	u.drawSizeIndicators()
+}
+
+// EndDrag drops whatever is being dragged where the mouse was released
+func (u *Universe) EndDrag(x, y int) {
+	u.wmOpMutex.Lock()
+	defer u.wmOpMutex.Unlock()
+
+	d := u.drag
+	if d == nil || !d.moved && x == d.startX && y == d.startY {
+		u.drag = nil
+		return
+	}
+	dropped := false
+	if u.dragValid() {
+		if d.pane != nil {
+			u.dropPane(x, y)
+			dropped = true
+		} else {
+			u.dragTo(x, y)
+		}
+	}
+	u.drag = nil
+
+	// clear the size indicators or the drop preview
+	u.refreshRenderRect()
+	if dropped {
+		u.updateSelection()
+	}
+}
+
+// dragValid returns whether what is being dragged is still where it was,
+// since panes may have died or the workspace changed since the drag began
+func (u *Universe) dragValid() bool {
+	d := u.drag
+	if d == nil {
+		return false
+	}
+	w := d.workspace
+	if w != u.workspaces[u.selectionIdx] || w.doFullscreen {
+		return false
+	}
+	if d.float != nil {
+		for _, f := range w.floating {
+			if f == d.float {
+				return true
+			}
+		}
+		return false
+	}
+	if d.pane != nil {
+		_, _, ok := w.contents.findParent(d.pane)
+		return ok
+	}
+	for _, div := range d.dividers {
+		if div.s.mode != modeSplit || div.idx+1 >= len(div.s.elements) {
+			return false
+		}
+		if div.s != w.contents && !w.contents.containsSplit(div.s) {
+			return false
+		}
+	}
+	return true
+}
+
+func (u *Universe) dragTo(x, y int) {
+	d := u.drag
+	w := d.workspace
+	if d.float != nil {
+		r := d.floatRect
+		dx, dy := x-d.startX, y-d.startY
+		if d.moveFloat {
+			r.X += dx
+			r.Y += dy
+		} else {
+			if d.startX == r.X+r.W-1 {
+				r.W += dx
+			}
+			if d.startY == r.Y+r.H-1 {
+				r.H += dy
+			}
+		}
+		d.float.rect = w.clampFloat(r)
+		u.refreshRenderRect()
+		return
+	}
+
+	for _, div := range d.dividers {
+		div.dragTo(x, y)
+	}
+	u.redrawAllLines()
+	u.drawSelectionBorder()
+}
+
+// dragTo moves the divider to the given coordinates, leaving at least one
+// cell on either side of it
+func (div divider) dragTo(x, y int) {
+	s := div.s
+	firstRec := s.elements[div.idx].contents.GetRenderRect()
+	secondRec := s.elements[div.idx+1].contents.GetRenderRect()
+
+	var combinedSize, wantedRelativeBorderPos int
+	if s.verticallyStacked {
+		combinedSize = firstRec.H + secondRec.H
+		wantedRelativeBorderPos = y - firstRec.Y
+	} else {
+		combinedSize = firstRec.W + secondRec.W
+		wantedRelativeBorderPos = x - firstRec.X
+	}
+	if combinedSize < 2 {
+		return
+	}
+	if wantedRelativeBorderPos < 1 {
+		wantedRelativeBorderPos = 1
+	} else if wantedRelativeBorderPos > combinedSize-1 {
+		wantedRelativeBorderPos = combinedSize - 1
+	}
+
+	wantedBorderRatio := float32(wantedRelativeBorderPos) / float32(combinedSize)
+	totalProportion := s.elements[div.idx].size + s.elements[div.idx+1].size
+
+	s.elements[div.idx].size = wantedBorderRatio * totalProportion
+	s.elements[div.idx+1].size = (1 - wantedBorderRatio) * totalProportion
+	s.refreshRenderRect(false)
+}
+
+// dividersAt returns the divider at the given coordinates, along with the
+// one meeting it there if the coordinates are on a T-junction
+func (s *workspace) dividersAt(x, y int) []divider {
+	first, ok := s.contents.dividerAt(x, y)
+	if !ok {
+		return nil
+	}
+
+	// a divider meeting this one ends right beside it
+	s1, s2 := first.s.elements[first.idx], first.s.elements[first.idx+1]
+	r1, r2 := s1.contents.GetRenderRect(), s2.contents.GetRenderRect()
+	var probes [][2]int
+	if first.s.verticallyStacked {
+		probes = [][2]int{{x, r1.Y + r1.H - 1}, {x, r2.Y}}
+	} else {
+		probes = [][2]int{{r1.X + r1.W - 1, y}, {r2.X, y}}
+	}
+	for _, p := range probes {
+		if second, ok := s.contents.dividerAt(p[0], p[1]); ok && second.s.verticallyStacked != first.s.verticallyStacked {
+			return []divider{first, second}
+		}
+	}
+	return []divider{first}
+}
+
+// dividerAt finds the divider at the given coordinates, which is as wide as
+// the gap between panes if there is one
+func (s *split) dividerAt(x, y int) (divider, bool) {
+	if s.mode != modeSplit {
+		// there are no dividers between tabs, but the visible child may have some
+		if child, ok := s.elements[s.selectionIdx].contents.(Container); ok {
+			return child.dividerAt(x, y)
+		}
+		return divider{}, false
+	}
+
+	gap := s.dividerWidth()
+	for idx, n := range s.elements {
+		r := n.contents.GetRenderRect()
+
+		horiz := !s.verticallyStacked && r.X+r.W <= x && x < r.X+r.W+gap && r.Y <= y && y < r.Y+r.H
+		vert := s.verticallyStacked && r.Y+r.H <= y && y < r.Y+r.H+gap && r.X <= x && x < r.X+r.W
+		if (horiz || vert) && idx+1 < len(s.elements) {
+			return divider{s, idx}, true
+		}
+
+		if r.contains(x, y) {
+			if child, ok := n.contents.(Container); ok {
+				return child.dividerAt(x, y)
+			}
+			return divider{}, false
+		}
+	}
+	return divider{}, false
+}
+
+// containsSplit returns whether t is somewhere below s
+func (s *split) containsSplit(t *split) bool {
+	for _, e := range s.elements {
+		if child, ok := e.contents.(*split); ok {
+			if child == t || child.containsSplit(t) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// drawSizeIndicators labels each pane being resized with its size
+func (u *Universe) drawSizeIndicators() {
+	d := u.drag
+	w := d.workspace
+	if d.float != nil {
+		if !d.moveFloat {
+			// floats cover the tiled panes, so they draw past the workspace
+			w.drawSizeIndicator(w.renderer.Renderer, d.float.contents.GetRenderRect())
+		}
+		return
+	}
+
+	resized := []Node{}
+	for _, div := range d.dividers {
+		for _, e := range div.s.elements[div.idx : div.idx+2] {
+			switch child := e.contents.(type) {
+			case Container:
+				resized = append(resized, child.leaves()...)
+			default:
+				resized = append(resized, child)
+			}
+		}
+	}
+	drawn := map[Node]bool{}
+	for _, n := range resized {
+		if !drawn[n] {
+			drawn[n] = true
+			w.drawSizeIndicator(w.renderer, n.GetRenderRect())
+		}
+	}
+}
+
+// drawSizeIndicator writes a rect's size, as columns × rows, in its middle
+func (s *workspace) drawSizeIndicator(renderer ecma48.Renderer, r Rect) {
+	if s.renderer.hidden {
+		return
+	}
+	label := []rune(fmt.Sprintf(" %d×%d ", r.W, r.H))
+	if len(label) > r.W {
+		return
+	}
+	x := r.X + (r.W-len(label))/2
+	y := r.Y + r.H/2
+	for i, ch := range label {
+		renderer.HandleCh(ecma48.PositionedChar{
+			Rune:   ch,
+			Cursor: ecma48.Cursor{X: x + i, Y: y, Style: s.u.settings.Theme.SizeIndicator},
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package wm
+
+import (
+	"github.com/aaronjanse/3mux/ecma48"
+)
+
+// A mouseTracker is a Node whose program can ask to be sent mouse events
+type mouseTracker interface {
+	// SendMouse passes a mouse event, in the node's own coordinates, to its
+	// program, returning false if the program didn't ask for it
+	SendMouse(ev ecma48.Parsed) bool
+}
+
+// ForwardMouse sends a mouse event to the program in the pane under the
+// pointer if that program asked for mouse events, and returns false if the
+// event is left for 3mux instead. Borders are always left for 3mux, as is
+// anything done while holding Shift and a left press while holding Alt, which
+// grabs the pane to drag it. Once a program is sent a press, it is
+// sent the drags and release that follow, wherever they are.
+func (u *Universe) ForwardMouse(ev ecma48.Parsed) bool {
+	u.wmOpMutex.Lock()
+	defer u.wmOpMutex.Unlock()
+
+	x, y, mods, ok := mouseEventInfo(ev)
+	if !ok {
+		return false
+	}
+
+	var target Node
+	switch e := ev.(type) {
+	case ecma48.MouseDown:
+		u.mouseGrab = nil
+		if mods.Shift || mods.Alt && e.Button == ecma48.LeftButton {
+			return false
+		}
+		target = u.workspaces[u.selectionIdx].paneAt(x, y)
+	case ecma48.MouseDrag, ecma48.MouseUp:
+		target = u.mouseGrab
+		if _, release := ev.(ecma48.MouseUp); release {
+			u.mouseGrab = nil
+		}
+		if target == nil || target.IsDead() {
+			return false
+		}
+	default:
+		if mods.Shift {
+			return false
+		}
+		target = u.workspaces[u.selectionIdx].paneAt(x, y)
+	}
+
+	t, ok := target.(mouseTracker)
+	if !ok {
+		return false
+	}
+	r := target.GetRenderRect()
+	if !t.SendMouse(translateMouse(ev, -r.X, -r.Y)) {
+		return false
+	}
+	if _, press := ev.(ecma48.MouseDown); press {
+		u.mouseGrab = target
+	}
+	return true
+}
+
+// paneAt returns the pane shown at the given coordinates, not counting the
+// borders around it
+func (s *workspace) paneAt(x, y int) Node {
+	if f := s.floatAt(x, y); f != nil {
+		if f.inner().contains(x, y) {
+			return f.contents
+		}
+		return nil
+	}
+	if s.doFullscreen {
+		switch n := s.selectedTarget().(type) {
+		case *split:
+			return n.leafAt(x, y)
+		default:
+			if n.GetRenderRect().contains(x, y) {
+				return n
+			}
+			return nil
+		}
+	}
+	return s.contents.leafAt(x, y)
+}
+
+// mouseEventInfo returns where a mouse event happened and the modifier keys
+// held during it
+func mouseEventInfo(ev ecma48.Parsed) (x, y int, mods ecma48.MouseMods, ok bool) {
+	switch e := ev.(type) {
+	case ecma48.MouseDown:
+		return e.X, e.Y, e.MouseMods, true
+	case ecma48.MouseUp:
+		return e.X, e.Y, e.MouseMods, true
+	case ecma48.MouseDrag:
+		return e.X, e.Y, e.MouseMods, true
+	case ecma48.MouseMove:
+		return e.X, e.Y, e.MouseMods, true
+	case ecma48.ScrollUp:
+		return e.X, e.Y, e.MouseMods, true
+	case ecma48.ScrollDown:
+		return e.X, e.Y, e.MouseMods, true
+	}
+	return 0, 0, ecma48.MouseMods{}, false
+}
+
+// translateMouse moves a mouse event by the given offset
+func translateMouse(ev ecma48.Parsed, dx, dy int) ecma48.Parsed {
+	switch e := ev.(type) {
+	case ecma48.MouseDown:
+		e.X, e.Y = e.X+dx, e.Y+dy
+		return e
+	case ecma48.MouseUp:
+		e.X, e.Y = e.X+dx, e.Y+dy
+		return e
+	case ecma48.MouseDrag:
+		e.X, e.Y = e.X+dx, e.Y+dy
+		return e
+	case ecma48.MouseMove:
+		e.X, e.Y = e.X+dx, e.Y+dy
+		return e
+	case ecma48.ScrollUp:
+		e.X, e.Y = e.X+dx, e.Y+dy
+		return e
+	case ecma48.ScrollDown:
+		e.X, e.Y = e.X+dx, e.Y+dy
+		return e
+	}
+	return ev
+}
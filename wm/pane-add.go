@@ -13,6 +13,7 @@ func (u *Universe) AddPane() error {
 		return err
 	}
 	u.simplify()
+	u.autoBalance()
 	u.refreshRenderRect() // FIXME only needs to redraw lines!
 	u.updateSelection()
 	return nil
@@ -22,6 +23,9 @@ func (s *workspace) addPane() error {
 	if s.doFullscreen {
 		return errors.New("cannot add pane while one is fullscreen")
 	}
+	// new panes are tiled, so select the tiled panes first
+	s.floatFocused = false
+	s.contents.clearSelectedWhole()
 	s.contents.addPane()
 	return nil
 }
@@ -38,21 +42,7 @@ func (s *split) addPane() {
 			return
 		}
 
-		size := float32(1) / float32(len(s.elements)+1)
-
-		// resize siblings
-		scaleFactor := float32(1) - size
-		for i := range s.elements {
-			s.elements[i].size *= scaleFactor
-		}
-
-		// add new child
-		createdTerm := s.newPane(s.renderer)
-		createdTerm.SetDeathHandler(s.handleChildDeath)
-		s.elements = append(s.elements, SizedNode{
-			size:     size,
-			contents: createdTerm,
-		})
+		s.appendNode(s.newPane(s.u.renderer, PaneSpec{}))
 
 		// update selection to new child
 		s.selectionIdx = len(s.elements) - 1
@@ -68,6 +58,7 @@ func (u *Universe) AddPaneTmux(vert bool) error {
 		return err
 	}
 	u.simplify()
+	u.autoBalance()
 	u.refreshRenderRect() // FIXME only needs to redraw lines!
 	u.updateSelection()
 	return nil
@@ -77,6 +68,8 @@ func (s *workspace) addPaneTmux(vert bool) error {
 	if s.doFullscreen {
 		return errors.New("cannot add pane while one is fullscreen")
 	}
+	s.floatFocused = false
+	s.contents.clearSelectedWhole()
 	s.contents.addPaneTmux(vert)
 	return nil
 }
@@ -91,7 +84,7 @@ func (s *split) addPaneTmux(vert bool) {
 	case Node:
 		s.elements[s.selectionIdx].contents = newSplit(
 			s.renderer, s.u, s.handleChildDeath, x.GetRenderRect(), vert,
-			1, []Node{x, s.newPane(s.renderer)}, s.newPane,
+			1, []Node{x, s.newPane(s.u.renderer, PaneSpec{})}, s.newPane,
 		)
 		s.refreshRenderRect(false)
 	}
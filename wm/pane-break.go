@@ -0,0 +1,154 @@
+package wm
+
+import (
+	"errors"
+	"fmt"
+)
+
+func init() {
+	for i := 1; i <= workspaceCount; i++ {
+		num := i
+		FuncNames[fmt.Sprintf("join-pane-horiz-%d", num)] = func(u *Universe) error {
+			return u.JoinPane(num, false)
+		}
+		FuncNames[fmt.Sprintf("join-pane-vert-%d", num)] = func(u *Universe) error {
+			return u.JoinPane(num, true)
+		}
+	}
+}
+
+// BreakPane moves the selected pane into a new workspace of its own and
+// shows that workspace
+func (u *Universe) BreakPane() error {
+	u.wmOpMutex.Lock()
+	defer u.wmOpMutex.Unlock()
+
+	src := u.workspaces[u.selectionIdx]
+	if src.doFullscreen {
+		return errors.New("cannot move pane while one is fullscreen")
+	}
+	if len(src.panes()) <= 1 {
+		return errors.New("pane is already alone in its workspace")
+	}
+
+	num := 1
+	for {
+		if _, ok := u.workspaceIdx(num); !ok {
+			break
+		}
+		num++
+	}
+
+	p := src.detachSelected()
+	if p == nil {
+		return errors.New("no pane to move")
+	}
+	if len(src.contents.elements) == 0 {
+		src.retile()
+	}
+	u.simplify()
+	u.showWorkspace(u.addWorkspace(num, p))
+	return nil
+}
+
+// JoinPane moves the selected pane next to the selected pane of the workspace
+// with the given number, splitting vertically (one above the other) or
+// horizontally, and shows that workspace
+func (u *Universe) JoinPane(num int, vertical bool) error {
+	u.wmOpMutex.Lock()
+	defer u.wmOpMutex.Unlock()
+
+	src := u.workspaces[u.selectionIdx]
+	if src.doFullscreen {
+		return errors.New("cannot move pane while one is fullscreen")
+	}
+	dstIdx, ok := u.workspaceIdx(num)
+	if !ok {
+		return fmt.Errorf("workspace %d does not exist", num)
+	}
+	dst := u.workspaces[dstIdx]
+	if dst.doFullscreen {
+		return fmt.Errorf("workspace %d has a fullscreen pane", num)
+	}
+	if dst == src && len(src.contents.leaves()) <= 1 && !src.floatFocused {
+		return errors.New("no other pane to join")
+	}
+
+	p := src.detachSelected()
+	if p == nil {
+		return errors.New("no pane to move")
+	}
+	dst.graft(p, vertical)
+
+	if len(src.contents.elements) == 0 && !src.retile() {
+		u.simplify()
+		u.showWorkspace(dstIdx)
+		u.removeWorkspace(src)
+		return nil
+	}
+
+	u.simplify()
+	if dst == src {
+		u.refreshRenderRect()
+		u.updateSelection()
+	} else {
+		dstIdx, _ = u.workspaceIdx(num)
+		u.showWorkspace(dstIdx)
+	}
+	return nil
+}
+
+// detachSelected removes the selected pane, whether tiled or floating,
+// without killing it
+func (s *workspace) detachSelected() Node {
+	if f := s.selectedFloat(); f != nil {
+		s.removeFloat(f)
+		return f.contents
+	}
+	s.contents.clearSelectedWhole()
+	return s.contents.detachSelected()
+}
+
+// graft puts a node next to the selected tiled pane and selects it
+func (s *workspace) graft(n Node, vertical bool) {
+	s.floatFocused = false
+	s.contents.clearSelectedWhole()
+
+	target := s.contents.getSelectedNode()
+	parent, idx, ok := s.contents.findParent(target)
+	if !ok {
+		// the tree is empty
+		s.contents.appendNode(n)
+		s.contents.selectNode(n)
+		return
+	}
+
+	parent.insertBeside(idx, n, vertical, true)
+	s.contents.selectNode(n)
+}
+
+// insertBeside puts a node before or after the split's child idx, splitting
+// vertically (one above the other) or horizontally
+func (s *split) insertBeside(idx int, n Node, vertical, after bool) {
+	if s.mode == modeSplit && s.verticallyStacked == vertical {
+		// share the target's space rather than reshaping the tree
+		size := s.elements[idx].size / 2
+		s.elements[idx].size = size
+		if after {
+			idx++
+		}
+		n.SetDeathHandler(s.handleChildDeath)
+		s.elements = append(s.elements[:idx], append([]SizedNode{{size: size, contents: n}}, s.elements[idx:]...)...)
+		return
+	}
+
+	target := s.elements[idx].contents
+	children, selectionIdx := []Node{n, target}, 0
+	if after {
+		children, selectionIdx = []Node{target, n}, 1
+	}
+	s.elements[idx].contents = newSplit(
+		s.renderer, s.u, s.handleChildDeath, target.GetRenderRect(),
+		vertical, selectionIdx, children, s.newPane,
+	)
+}
@@ -0,0 +1,198 @@
+package wm
+
+import (
+	"github.com/aaronjanse/3mux/ecma48"
+)
+
+// A dropZone is where a dragged pane would land: beside the target pane in
+// the given direction, or in its place if swap is set
+type dropZone struct {
+	target Node
+	dir    Direction
+	swap   bool
+}
+
+// grabAt returns the tiled node whose title or top border is at the given
+// coordinates, and whether it was the title itself rather than the border
+// around it. Tabs count as titles.
+func (s *workspace) grabAt(x, y int) (n Node, onTitle bool) {
+	return s.contents.grabAt(x, y, true)
+}
+
+// grabAt walks the split like drawPaneTitles, since only panes with a title
+// drawn above them can be grabbed by it
+func (s *split) grabAt(x, y int, above bool) (Node, bool) {
+	if s.mode != modeSplit {
+		if idx := s.titleAt(x, y); idx >= 0 {
+			return s.elements[idx].contents, true
+		}
+	}
+	for idx, e := range s.elements {
+		if !s.childVisible(idx) {
+			continue
+		}
+		childAbove := above
+		if s.mode != modeSplit {
+			childAbove = false
+		} else if s.verticallyStacked && idx > 0 {
+			childAbove = true
+		}
+
+		switch child := e.contents.(type) {
+		case *split:
+			if n, onTitle := child.grabAt(x, y, childAbove); n != nil {
+				return n, onTitle
+			}
+		default:
+			if !childAbove || !s.u.settings.PaneTitles {
+				continue
+			}
+			r := child.GetRenderRect()
+			if y != r.Y-1 || x < r.X || x >= r.X+r.W {
+				continue
+			}
+			// see drawPaneTitle
+			titleW := len([]rune(" " + child.Title() + " "))
+			if titleW > r.W-2 {
+				titleW = r.W - 2
+			}
+			return child, r.X+1 <= x && x < r.X+1+titleW
+		}
+	}
+	return nil, false
+}
+
+// leafAt returns the tiled pane shown at the given coordinates
+func (s *split) leafAt(x, y int) Node {
+	for idx, e := range s.elements {
+		if !s.childVisible(idx) || !e.contents.GetRenderRect().contains(x, y) {
+			continue
+		}
+		if child, ok := e.contents.(*split); ok {
+			return child.leafAt(x, y)
+		}
+		return e.contents
+	}
+	return nil
+}
+
+// dropZoneAt returns where the dragged pane would land if dropped at the
+// given coordinates
+func (s *workspace) dropZoneAt(dragged Node, x, y int) (dropZone, bool) {
+	if s.floatAt(x, y) != nil {
+		return dropZone{}, false
+	}
+	target := s.contents.leafAt(x, y)
+	if target == nil || target == dragged {
+		return dropZone{}, false
+	}
+	if c, ok := dragged.(*split); ok {
+		// a container can't be dropped inside itself
+		if _, _, inside := c.findParent(target); inside {
+			return dropZone{}, false
+		}
+	}
+
+	// the middle of the target swaps, and elsewhere is nearest an edge
+	r := target.GetRenderRect()
+	fx := (float32(x-r.X) + 0.5) / float32(r.W)
+	fy := (float32(y-r.Y) + 0.5) / float32(r.H)
+	if 0.25 < fx && fx < 0.75 && 0.25 < fy && fy < 0.75 {
+		return dropZone{target: target, swap: true}, true
+	}
+	z := dropZone{target: target, dir: Left}
+	dist := fx
+	for _, edge := range []struct {
+		dir  Direction
+		dist float32
+	}{{Right, 1 - fx}, {Up, fy}, {Down, 1 - fy}} {
+		if edge.dist < dist {
+			z.dir, dist = edge.dir, edge.dist
+		}
+	}
+	return z, true
+}
+
+// rect is the area the dropped pane would take up
+func (z dropZone) rect() Rect {
+	r := z.target.GetRenderRect()
+	switch {
+	case z.swap:
+	case z.dir == Left:
+		r.W -= r.W / 2
+	case z.dir == Right:
+		r.X += r.W / 2
+		r.W -= r.W / 2
+	case z.dir == Up:
+		r.H -= r.H / 2
+	case z.dir == Down:
+		r.Y += r.H / 2
+		r.H -= r.H / 2
+	}
+	return r
+}
+
+// previewDrop outlines where the dragged pane would land
+func (u *Universe) previewDrop(x, y int) {
+	d := u.drag
+	w := d.workspace
+	z, ok := w.dropZoneAt(d.pane, x, y)
+	if z == d.drop {
+		return
+	}
+	d.drop = z
+	u.refreshRenderRect()
+	if ok && !w.renderer.hidden {
+		w.drawOutline(z.rect(), u.settings.Theme.ActiveBorder)
+	}
+}
+
+// dropPane moves the dragged pane to wherever it was dropped
+func (u *Universe) dropPane(x, y int) {
+	d := u.drag
+	w := d.workspace
+	z, ok := w.dropZoneAt(d.pane, x, y)
+	if !ok {
+		return
+	}
+	w.contents.clearSelectedWhole()
+
+	if z.swap {
+		if u.swapPanes(d.pane, z.target) != nil {
+			return
+		}
+	} else {
+		parent, idx, _ := w.contents.findParent(d.pane)
+		parent.popElement(idx)
+		parent, idx, _ = w.contents.findParent(z.target)
+		parent.insertBeside(idx, d.pane, z.dir == Up || z.dir == Down, z.dir == Down || z.dir == Right)
+		u.simplify()
+	}
+	w.contents.selectNode(d.pane)
+}
+
+// drawOutline draws a frame just inside the given rect
+func (s *workspace) drawOutline(r Rect, style ecma48.Style) {
+	if r.W < 2 || r.H < 2 {
+		return
+	}
+	chars := s.u.settings.Theme.Chars
+	draw := func(ch rune, x, y int) {
+		s.renderer.HandleCh(ecma48.PositionedChar{
+			Rune:   ch,
+			Cursor: ecma48.Cursor{X: x, Y: y, Style: style},
+		})
+	}
+	for x := r.X + 1; x < r.X+r.W-1; x++ {
+		draw(chars.Horizontal, x, r.Y)
+		draw(chars.Horizontal, x, r.Y+r.H-1)
+	}
+	for y := r.Y + 1; y < r.Y+r.H-1; y++ {
+		draw(chars.Vertical, r.X, y)
+		draw(chars.Vertical, r.X+r.W-1, y)
+	}
+	draw(chars.TopLeft, r.X, r.Y)
+	draw(chars.TopRight, r.X+r.W-1, r.Y)
+	draw(chars.BottomLeft, r.X, r.Y+r.H-1)
+	draw(chars.BottomRight, r.X+r.W-1, r.Y+r.H-1)
+}
@@ -0,0 +1,115 @@
+package wm
+
+import (
+	"fmt"
+
+	"github.com/aaronjanse/3mux/ecma48"
+)
+
+// PaneInfo describes a pane for external tools
+type PaneInfo struct {
+	ID int `json:"id"`
+	// Workspace is the index of the pane's workspace, or -1 for panes hidden
+	// in the scratchpad
+	Workspace int  `json:"workspace"`
+	Rect      Rect `json:"rect"`
+	Selected  bool `json:"selected"`
+	Marked    bool `json:"marked"`
+	// Synced is set for panes that typed input is copied to
+	Synced bool `json:"synced"`
+}
+
+// ListPanes returns every pane in every workspace, in tree order
+func (u *Universe) ListPanes() []PaneInfo {
+	u.wmOpMutex.Lock()
+	defer u.wmOpMutex.Unlock()
+
+	selected := u.getSelectedNode()
+
+	out := []PaneInfo{}
+	for idx, w := range u.workspaces {
+		for _, n := range w.panes() {
+			out = append(out, PaneInfo{
+				ID:        n.ID(),
+				Workspace: idx,
+				Rect:      n.GetRenderRect(),
+				Selected:  n == selected,
+				Marked:    n == u.marked,
+				Synced:    w.isSynced(n),
+			})
+		}
+	}
+	for _, f := range u.scratchpad {
+		out = append(out, PaneInfo{
+			ID:        f.contents.ID(),
+			Workspace: -1,
+			Rect:      f.inner(),
+			Marked:    f.contents == u.marked,
+		})
+	}
+	return out
+}
+
+// SendToPane passes input to the pane with the given ID as if it were typed
+// while that pane was selected
+func (u *Universe) SendToPane(id int, in []ecma48.Output) error {
+	n, err := u.FindPane(id)
+	if err != nil {
+		return err
+	}
+	for _, x := range in {
+		n.HandleStdin(x)
+	}
+	return nil
+}
+
+// FindPane returns the pane with the given ID
+func (u *Universe) FindPane(id int) (Node, error) {
+	u.wmOpMutex.Lock()
+	defer u.wmOpMutex.Unlock()
+
+	n := u.findPane(id)
+	if n == nil {
+		return nil, fmt.Errorf("no pane with ID %d", id)
+	}
+	return n, nil
+}
+
+func (u *Universe) findPane(id int) Node {
+	for _, w := range u.workspaces {
+		for _, n := range w.panes() {
+			if n.ID() == id {
+				return n
+			}
+		}
+	}
+	for _, f := range u.scratchpad {
+		if f.contents.ID() == id {
+			return f.contents
+		}
+	}
+	return nil
+}
+
+// panes returns all of a workspace's panes, tiled ones first
+func (s *workspace) panes() []Node {
+	out := s.contents.leaves()
+	for _, f := range s.floating {
+		out = append(out, f.contents)
+	}
+	return out
+}
+
+// leaves returns all panes within a split, in tree order
+func (s *split) leaves() []Node {
+	out := []Node{}
+	for _, e := range s.elements {
+		switch child := e.contents.(type) {
+		case Container:
+			out = append(out, child.leaves()...)
+		case Node:
+			out = append(out, child)
+		}
+	}
+	return out
+}
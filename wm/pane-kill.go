@@ -4,12 +4,22 @@ func (u *Universe) KillPane() {
 	u.wmOpMutex.Lock()
 	defer u.wmOpMutex.Unlock()
 
-	allDead := u.workspaces[u.selectionIdx].killPane()
-	if !allDead {
+	w := u.workspaces[u.selectionIdx]
+	if f := w.selectedFloat(); f != nil {
+		f.contents.Kill()
+		w.removeFloat(f)
+		u.refreshRenderRect()
+		u.updateSelection()
+		return
+	}
+
+	allDead := w.killPane()
+	if !allDead || w.retile() {
+		u.autoBalance()
 		u.refreshRenderRect()
 		u.updateSelection()
 	} else {
-		u.onDeath(nil)
+		u.removeWorkspace(w)
 	}
 }
 
@@ -22,7 +32,7 @@ func (s *split) killPane() (dead bool) {
 	if len(s.elements) == 0 {
 		return
 	}
-	switch child := s.elements[s.selectionIdx].contents.(type) {
+	switch child := s.selectedChild().(type) {
 	case Container:
 		child.killPane()
 	case Node:
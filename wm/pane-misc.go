@@ -6,7 +6,7 @@ func (u *Universe) ToggleSearch() {
 	u.wmOpMutex.Lock()
 	defer u.wmOpMutex.Unlock()
 
-	u.workspaces[u.selectionIdx].contents.ToggleSearch()
+	u.getSelectedNode().ToggleSearch()
 }
 
 func (s *split) ToggleSearch() {
@@ -16,25 +16,54 @@ func (s *split) ToggleSearch() {
 	s.elements[s.selectionIdx].contents.ToggleSearch()
 }
 
-func (u *Universe) ScrollUp() {
-	u.workspaces[u.selectionIdx].contents.ScrollUp()
+// ScrollUp scrolls the pane at the given coordinates, which need not be the
+// selected one
+func (u *Universe) ScrollUp(x, y int) {
+	u.wmOpMutex.Lock()
+	defer u.wmOpMutex.Unlock()
+
+	if n := u.workspaces[u.selectionIdx].paneAt(x, y); n != nil {
+		n.ScrollUp(u.settings.ScrollLines)
+	}
 }
-func (s *split) ScrollUp() {
+func (s *split) ScrollUp(lines int) {
 	if len(s.elements) == 0 {
 		return
 	}
-	s.elements[s.selectionIdx].contents.ScrollUp()
+	s.elements[s.selectionIdx].contents.ScrollUp(lines)
 }
 
-func (u *Universe) ScrollDown() {
-	u.workspaces[u.selectionIdx].contents.ScrollDown()
+// ScrollDown scrolls the pane at the given coordinates, which need not be the
+// selected one
+func (u *Universe) ScrollDown(x, y int) {
+	u.wmOpMutex.Lock()
+	defer u.wmOpMutex.Unlock()
+
+	if n := u.workspaces[u.selectionIdx].paneAt(x, y); n != nil {
+		n.ScrollDown(u.settings.ScrollLines)
+	}
 }
-func (s *split) ScrollDown() {
-	s.elements[s.selectionIdx].contents.ScrollDown()
+func (s *split) ScrollDown(lines int) {
+	if len(s.elements) == 0 {
+		return
+	}
+	s.elements[s.selectionIdx].contents.ScrollDown(lines)
 }
 
 func (u *Universe) HandleStdin(in ecma48.Output) {
-	u.workspaces[u.selectionIdx].contents.HandleStdin(in)
+	// the panes are written to without the lock, since a pty that isn't
+	// being read from would block every other operation
+	u.wmOpMutex.Lock()
+	selected := u.getSelectedNode()
+	targets := u.workspaces[u.selectionIdx].syncTargets()
+	u.wmOpMutex.Unlock()
+
+	selected.HandleStdin(in)
+	for _, n := range targets {
+		if n != selected {
+			n.HandleStdin(in)
+		}
+	}
 }
 func (s *split) HandleStdin(in ecma48.Output) {
 	s.elements[s.selectionIdx].contents.HandleStdin(in)
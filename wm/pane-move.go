@@ -21,6 +21,10 @@ func (s *workspace) moveWindow(dir Direction) error {
 	if s.doFullscreen {
 		return errors.New("cannot move window while one is fullscreen")
 	}
+	if f := s.selectedFloat(); f != nil {
+		s.moveFloat(f, dir)
+		return nil
+	}
 	bubble, _, p := s.contents.moveWindow(dir)
 	if bubble {
 		if dir == Up || dir == Left {
@@ -39,13 +43,13 @@ func (s *workspace) moveWindow(dir Direction) error {
 }
 
 func (s *split) moveWindow(d Direction) (bubble bool, superBubble bool, p Node) {
-	alignedForwards := (!s.verticallyStacked && d == Right) || (s.verticallyStacked && d == Down)
-	alignedBackward := (!s.verticallyStacked && d == Left) || (s.verticallyStacked && d == Up)
+	alignedForwards := (!s.vertical() && d == Right) || (s.vertical() && d == Down)
+	alignedBackward := (!s.vertical() && d == Left) || (s.vertical() && d == Up)
 
 	if len(s.elements) == 0 {
 		return
 	}
-	switch child := s.elements[s.selectionIdx].contents.(type) {
+	switch child := s.selectedChild().(type) {
 	case Container:
 		bubble, superBubble, p := child.moveWindow(d)
 		if superBubble {
@@ -83,6 +87,7 @@ func (s *split) moveWindow(d Direction) (bubble bool, superBubble bool, p Node)
 					s.elements[idx:]...)...)
 		}
 	case Node:
+		child = unwrapWhole(child)
 		if alignedBackward {
 			if s.selectionIdx == 0 {
 				s.popElement(s.selectionIdx)
@@ -106,10 +111,10 @@ func (s *split) moveWindow(d Direction) (bubble bool, superBubble bool, p Node)
 
 			s.selectionIdx++
 		} else {
-			switch len(s.elements) {
-			case 0:
+			switch {
+			case len(s.elements) == 0:
 				panic("cannot move without elements")
-			case 2:
+			case len(s.elements) == 2 && s.mode == modeSplit:
 				s.verticallyStacked = !s.verticallyStacked
 			default:
 				s.popElement(s.selectionIdx)
@@ -9,6 +9,10 @@ func (u *Universe) ResizePane(d Direction) {
 }
 
 func (s *workspace) resizePane(d Direction) {
+	if f := s.selectedFloat(); f != nil {
+		s.resizeFloat(f, d)
+		return
+	}
 	s.contents.resizePane(d)
 }
 
@@ -19,13 +23,20 @@ func (s *split) resizePane(d Direction) (bubble bool) {
 	if len(s.elements) == 0 {
 		return false
 	}
-	switch child := s.elements[s.selectionIdx].contents.(type) {
+	switch child := s.selectedChild().(type) {
 	case Container:
 		bubble := child.resizePane(d)
 		if bubble {
+			if s.mode != modeSplit {
+				return true
+			}
 			s.resizeSelectedChild(d)
 		}
 	case Node:
+		if s.mode != modeSplit {
+			// only the selected child is shown, so there's nothing to resize against
+			return true
+		}
 		if s.verticallyStacked {
 			if d == Left || d == Right {
 				return true
@@ -0,0 +1,179 @@
+package wm
+
+import (
+	"errors"
+)
+
+func init() {
+	dirs := map[string]Direction{"up": Up, "down": Down, "left": Left, "right": Right}
+	for name, dir := range dirs {
+		d := dir
+		FuncNames["swap-pane-"+name] = func(u *Universe) error { return u.SwapPane(d) }
+	}
+}
+
+// MarkPane marks the selected pane for SwapWithMarked, or unmarks it if it is
+// marked already
+func (u *Universe) MarkPane() {
+	u.wmOpMutex.Lock()
+	defer u.wmOpMutex.Unlock()
+
+	n := u.getSelectedNode()
+	if _, ok := n.(*split); ok {
+		return
+	}
+	if u.marked == n {
+		u.marked = nil
+	} else {
+		u.marked = n
+	}
+	u.refreshRenderRect()
+}
+
+// SwapWithMarked swaps the selected pane with the marked one, wherever it is.
+// The selection stays in place, so it moves to the marked pane.
+func (u *Universe) SwapWithMarked() error {
+	u.wmOpMutex.Lock()
+	defer u.wmOpMutex.Unlock()
+
+	if u.marked == nil || u.marked.IsDead() {
+		u.marked = nil
+		return errors.New("no pane is marked")
+	}
+	if u.workspaces[u.selectionIdx].doFullscreen {
+		return errors.New("cannot swap panes while one is fullscreen")
+	}
+
+	selected := u.getSelectedNode()
+	if selected == u.marked {
+		return nil
+	}
+	err := u.swapPanes(selected, u.marked)
+	if err != nil {
+		return err
+	}
+
+	u.refreshRenderRect()
+	u.updateSelection()
+	return nil
+}
+
+// SwapPane swaps the selected pane with its neighbor in the given direction,
+// leaving the shape of the tree alone. The selection follows the pane.
+func (u *Universe) SwapPane(d Direction) error {
+	u.wmOpMutex.Lock()
+	defer u.wmOpMutex.Unlock()
+
+	w := u.workspaces[u.selectionIdx]
+	if w.doFullscreen {
+		return errors.New("cannot swap panes while one is fullscreen")
+	}
+	if w.floatFocused {
+		return errors.New("cannot swap a floating pane with a neighbor")
+	}
+
+	selected := w.contents.getSelectedNode()
+	w.contents.moveSelection(d)
+	neighbor := w.contents.getSelectedNode()
+	if neighbor == selected {
+		return nil
+	}
+	err := u.swapPanes(selected, neighbor)
+	if err != nil {
+		return err
+	}
+
+	u.refreshRenderRect()
+	u.updateSelection()
+	return nil
+}
+
+func (u *Universe) swapPanes(a, b Node) error {
+	slotA, okA := u.findSlot(a)
+	slotB, okB := u.findSlot(b)
+	if !okA || !okB {
+		return errors.New("pane not found")
+	}
+	slotA.set(b)
+	slotB.set(a)
+	u.workspaces[u.selectionIdx].contents.clearSelectedWhole()
+	return nil
+}
+
+// visibleMark returns the marked pane if it is tiled in the given workspace
+func (u *Universe) visibleMark(w *workspace) Node {
+	if u.marked == nil || u.marked.IsDead() {
+		return nil
+	}
+	for _, n := range w.contents.leaves() {
+		if n == u.marked {
+			return n
+		}
+	}
+	return nil
+}
+
+// A paneSlot is somewhere a pane can be: within a split, floating above a
+// workspace, or hidden in the scratchpad
+type paneSlot struct {
+	u     *Universe
+	split *split
+	idx   int
+
+	float *floatingPane
+	// w is the workspace of a floating pane, or nil for the scratchpad
+	w *workspace
+}
+
+// findSlot returns where a pane is
+func (u *Universe) findSlot(n Node) (paneSlot, bool) {
+	for _, w := range u.workspaces {
+		if s, idx, ok := w.contents.findParent(n); ok {
+			return paneSlot{u: u, split: s, idx: idx}, true
+		}
+		for _, f := range w.floating {
+			if f.contents == n {
+				return paneSlot{u: u, float: f, w: w}, true
+			}
+		}
+	}
+	for _, f := range u.scratchpad {
+		if f.contents == n {
+			return paneSlot{u: u, float: f}, true
+		}
+	}
+	return paneSlot{}, false
+}
+
+// set puts a pane in the slot in place of whatever was there
+func (p paneSlot) set(n Node) {
+	switch {
+	case p.split != nil:
+		p.split.elements[p.idx].contents = n
+		n.SetDeathHandler(p.split.handleChildDeath)
+	case p.w != nil:
+		p.float.contents = n
+		n.SetDeathHandler(p.w.handleFloatDeath)
+	default:
+		p.float.contents = n
+		n.SetDeathHandler(p.u.handleScratchpadDeath)
+		n.SetHidden(true)
+		n.UpdateSelection(false)
+	}
+}
+
+// findParent returns the split directly containing the given node and the
+// node's index in it
+func (s *split) findParent(n Node) (*split, int, bool) {
+	for idx, e := range s.elements {
+		if e.contents == n {
+			return s, idx, true
+		}
+		if child, ok := e.contents.(*split); ok {
+			if p, i, ok := child.findParent(n); ok {
+				return p, i, true
+			}
+		}
+	}
+	return nil, 0, false
+}
@@ -0,0 +1,166 @@
+package wm
+
+import (
+	"github.com/aaronjanse/3mux/ecma48"
+)
+
+// A titleNotifier is a Node whose title can change on its own, like a pane
+// whose program sets its title
+type titleNotifier interface {
+	SetTitleHandler(func())
+}
+
+// wrapNewPane has each pane that the Universe creates report changes to its
+// title
+func (u *Universe) wrapNewPane(newPane NewPaneFunc) NewPaneFunc {
+	return func(renderer ecma48.Renderer, spec PaneSpec) Node {
+		n := newPane(renderer, spec)
+		if t, ok := n.(titleNotifier); ok {
+			t.SetTitleHandler(u.handleTitleChange)
+		}
+		return n
+	}
+}
+
+// handleTitleChange is called from a pane's own goroutines, which must not
+// wait on wmOpMutex since it may be needed to finish the current operation
+func (u *Universe) handleTitleChange() {
+	go func() {
+		u.wmOpMutex.Lock()
+		defer u.wmOpMutex.Unlock()
+
+		if u.dead || len(u.workspaces) == 0 {
+			return
+		}
+		w := u.workspaces[u.selectionIdx]
+		u.redrawAllLines()
+		u.drawSelectionBorder()
+		w.drawFloatBorders()
+		if u.enableStatusBar && !u.helpBar {
+			u.drawStatusBar()
+		}
+	}()
+}
+
+// titleRow returns whether the workspace's top row is reserved as a border
+// above the panes along it, so that every pane has a border for its title
+func (s *workspace) titleRow() bool {
+	return s.u.settings.PaneTitles && !s.doFullscreen
+}
+
+// drawTitleRow draws the border along the top of the workspace
+func (s *workspace) drawTitleRow() {
+	if !s.titleRow() {
+		return
+	}
+	theme := s.u.settings.Theme
+	r := s.contents.GetRenderRect()
+	if s.u.settings.InnerGap > 0 {
+		// with gaps, each pane's title has a line of its own instead
+		for x := r.X; x < r.X+r.W; x++ {
+			s.renderer.HandleCh(ecma48.PositionedChar{
+				Rune:   ' ',
+				Cursor: ecma48.Cursor{X: x, Y: r.Y - 1},
+			})
+		}
+		return
+	}
+	for x := r.X; x < r.X+r.W; x++ {
+		s.renderer.HandleCh(ecma48.PositionedChar{
+			Rune:   theme.Chars.Horizontal,
+			Cursor: ecma48.Cursor{X: x, Y: r.Y - 1, Style: theme.Border},
+		})
+	}
+	s.contents.drawTitleRowJunctions(r.X, r.Y)
+}
+
+// drawTitleRowJunctions joins the dividers between children along the top of
+// the workspace to the border above them
+func (s *split) drawTitleRowJunctions(left, top int) {
+	for idx, e := range s.elements {
+		if !s.childVisible(idx) {
+			continue
+		}
+		r := e.contents.GetRenderRect()
+		if r.Y != top {
+			continue
+		}
+		if r.X > left {
+			theme := s.u.settings.Theme
+			s.renderer.HandleCh(ecma48.PositionedChar{
+				Rune:   theme.Chars.TeeDown,
+				Cursor: ecma48.Cursor{X: r.X - 1, Y: r.Y - 1, Style: theme.Border},
+			})
+		}
+		if child, ok := e.contents.(*split); ok {
+			child.drawTitleRowJunctions(left, top)
+		}
+	}
+}
+
+// drawPaneTitles writes the title of each tiled pane into the border above it
+func (s *workspace) drawPaneTitles() {
+	if !s.titleRow() || s.renderer.hidden {
+		return
+	}
+	var selected Node
+	if !s.floatFocused {
+		selected = s.selectedTarget()
+	}
+	s.contents.drawPaneTitles(true, selected)
+}
+
+// drawPaneTitles writes the titles of the panes within the split. above is
+// whether the split's top edge is a border, which it isn't beneath the titles
+// of a tabbed or stacked split.
+func (s *split) drawPaneTitles(above bool, selected Node) {
+	for idx, e := range s.elements {
+		if !s.childVisible(idx) {
+			continue
+		}
+		childAbove := above
+		if s.mode != modeSplit {
+			childAbove = false
+		} else if s.verticallyStacked && idx > 0 {
+			childAbove = true
+		}
+
+		switch child := e.contents.(type) {
+		case *split:
+			child.drawPaneTitles(childAbove, selected)
+		default:
+			if childAbove {
+				s.drawPaneTitle(child, child == selected)
+			}
+		}
+	}
+}
+
+func (s *split) drawPaneTitle(n Node, selected bool) {
+	r := n.GetRenderRect()
+	style := s.u.settings.Theme.Border
+	if selected {
+		style = s.u.settings.Theme.ActiveBorder
+	}
+
+	if s.u.settings.InnerGap > 0 {
+		for i := 0; i < r.W; i++ {
+			s.renderer.HandleCh(ecma48.PositionedChar{
+				Rune:   s.u.settings.Theme.Chars.Horizontal,
+				Cursor: ecma48.Cursor{X: r.X + i, Y: r.Y - 1, Style: style},
+			})
+		}
+	}
+
+	// leave some of the border showing at either end
+	title := []rune(" " + n.Title() + " ")
+	for i, ch := range title {
+		if i >= r.W-2 {
+			break
+		}
+		s.renderer.HandleCh(ecma48.PositionedChar{
+			Rune:   ch,
+			Cursor: ecma48.Cursor{X: r.X + 1 + i, Y: r.Y - 1, Style: style},
+		})
+	}
+}
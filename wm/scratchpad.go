@@ -0,0 +1,121 @@
+package wm
+
+import (
+	"errors"
+)
+
+// MoveToScratchpad hides the selected pane in the scratchpad, from where
+// ToggleScratchpad brings it back above any workspace. It keeps running while
+// hidden.
+func (u *Universe) MoveToScratchpad() error {
+	u.wmOpMutex.Lock()
+	defer u.wmOpMutex.Unlock()
+
+	w := u.workspaces[u.selectionIdx]
+	if w.doFullscreen {
+		return errors.New("cannot move pane while one is fullscreen")
+	}
+
+	total := 0
+	for _, x := range u.workspaces {
+		total += len(x.panes())
+	}
+	if total <= 1 {
+		return errors.New("cannot move the last pane to the scratchpad")
+	}
+
+	f := w.selectedFloat()
+	if f != nil {
+		w.removeFloat(f)
+	} else {
+		p := w.contents.detachSelected()
+		if p == nil {
+			return errors.New("no pane to move")
+		}
+		f = &floatingPane{contents: p, rect: w.centeredRect(60)}
+	}
+	f.scratchpad = true
+	u.hideInScratchpad(f)
+
+	if len(w.contents.elements) == 0 && !w.retile() {
+		u.removeWorkspace(w)
+		return nil
+	}
+	u.simplify()
+	u.refreshRenderRect()
+	u.updateSelection()
+	return nil
+}
+
+// ToggleScratchpad shows the next pane from the scratchpad above the current
+// workspace. If a scratchpad pane is already shown, it is selected or, if it
+// is selected already, hidden again. One shown on another workspace is moved
+// here first, as in i3.
+func (u *Universe) ToggleScratchpad() error {
+	u.wmOpMutex.Lock()
+	defer u.wmOpMutex.Unlock()
+
+	w := u.workspaces[u.selectionIdx]
+	if w.doFullscreen {
+		return errors.New("cannot show the scratchpad while a pane is fullscreen")
+	}
+
+	var shown *floatingPane
+	shownOn := w
+	for _, x := range u.workspaces {
+		for _, f := range x.floating {
+			if f.scratchpad && (shown == nil || x == w) {
+				shown, shownOn = f, x
+			}
+		}
+	}
+
+	switch {
+	case shown != nil && shownOn != w:
+		shownOn.removeFloat(shown)
+		if len(shownOn.contents.elements) == 0 && !shownOn.retile() {
+			u.removeWorkspace(shownOn)
+		}
+		w.showFloat(shown)
+	case shown != nil && shown == w.selectedFloat():
+		w.removeFloat(shown)
+		u.hideInScratchpad(shown)
+	case shown != nil:
+		w.raiseFloat(shown)
+		w.floatFocused = true
+	case len(u.scratchpad) > 0:
+		f := u.scratchpad[0]
+		u.scratchpad = u.scratchpad[1:]
+		w.showFloat(f)
+	default:
+		return errors.New("scratchpad is empty")
+	}
+
+	u.refreshRenderRect()
+	u.updateSelection()
+	return nil
+}
+
+func (u *Universe) hideInScratchpad(f *floatingPane) {
+	f.contents.SetHidden(true)
+	f.contents.UpdateSelection(false)
+	f.contents.SetDeathHandler(u.handleScratchpadDeath)
+	u.scratchpad = append(u.scratchpad, f)
+}
+
+func (u *Universe) handleScratchpadDeath(err error) {
+	if err != nil {
+		u.handleChildDeath(err)
+		return
+	}
+
+	u.wmOpMutex.Lock()
+	defer u.wmOpMutex.Unlock()
+
+	u.pruneHistory()
+	for idx := len(u.scratchpad) - 1; idx >= 0; idx-- {
+		if u.scratchpad[idx].contents.IsDead() {
+			u.scratchpad = append(u.scratchpad[:idx], u.scratchpad[idx+1:]...)
+		}
+	}
+}
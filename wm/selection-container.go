@@ -0,0 +1,126 @@
+package wm
+
+import (
+	"errors"
+)
+
+// FocusParent selects the container holding the selection so that moving,
+// resizing, killing, and fullscreening act on all of it, like in i3. The
+// workspace's root can't be selected.
+func (u *Universe) FocusParent() error {
+	u.wmOpMutex.Lock()
+	defer u.wmOpMutex.Unlock()
+
+	w := u.workspaces[u.selectionIdx]
+	if w.doFullscreen {
+		return errors.New("cannot change focus while a pane is fullscreen")
+	}
+	if w.floatFocused {
+		return errors.New("floating panes have no parent container")
+	}
+
+	// path[0] is the root, and the selected pane sits below the last split
+	path := w.contents.selectionPath()
+	depth := len(path)
+	for idx := 1; idx < len(path); idx++ {
+		if path[idx].selectedWhole {
+			depth = idx
+			break
+		}
+	}
+	if depth-1 < 1 {
+		return errors.New("no parent container to select")
+	}
+
+	w.contents.clearSelectedWhole()
+	path[depth-1].selectedWhole = true
+	u.redrawAllLines()
+	u.drawSelectionBorder()
+	return nil
+}
+
+// FocusChild undoes FocusParent, selecting the container or pane within the
+// selected container
+func (u *Universe) FocusChild() error {
+	u.wmOpMutex.Lock()
+	defer u.wmOpMutex.Unlock()
+
+	w := u.workspaces[u.selectionIdx]
+	if w.doFullscreen {
+		return errors.New("cannot change focus while a pane is fullscreen")
+	}
+
+	path := w.contents.selectionPath()
+	for idx := 1; idx < len(path); idx++ {
+		if path[idx].selectedWhole {
+			path[idx].selectedWhole = false
+			if idx+1 < len(path) {
+				path[idx+1].selectedWhole = true
+			}
+			u.redrawAllLines()
+			u.drawSelectionBorder()
+			return nil
+		}
+	}
+	return errors.New("a pane is already selected")
+}
+
+// selectionPath returns the splits leading from this one to the selected pane
+func (s *split) selectionPath() []*split {
+	path := []*split{s}
+	if len(s.elements) > 0 {
+		if child, ok := s.elements[s.selectionIdx].contents.(*split); ok {
+			path = append(path, child.selectionPath()...)
+		}
+	}
+	return path
+}
+
+// clearSelectedWhole goes back to selecting a single pane
+func (s *split) clearSelectedWhole() {
+	s.selectedWhole = false
+	for _, e := range s.elements {
+		if child, ok := e.contents.(*split); ok {
+			child.clearSelectedWhole()
+		}
+	}
+}
+
+// selectedTarget returns the selected container, if any, or else the
+// selected pane
+func (s *workspace) selectedTarget() Node {
+	if f := s.selectedFloat(); f != nil {
+		return f.contents
+	}
+	path := s.contents.selectionPath()
+	for _, p := range path[1:] {
+		if p.selectedWhole {
+			return p
+		}
+	}
+	return s.contents.getSelectedNode()
+}
+
+// A wholeSplit hides a selected container's Container methods so that
+// operations which recurse down to the selected pane stop at it instead
+type wholeSplit struct {
+	Node
+}
+
+// selectedChild returns the selected child, wrapped in a wholeSplit if it is
+// a container selected as a whole
+func (s *split) selectedChild() Node {
+	child := s.elements[s.selectionIdx].contents
+	if c, ok := child.(*split); ok && c.selectedWhole {
+		return wholeSplit{c}
+	}
+	return child
+}
+
+// unwrapWhole returns the container within a wholeSplit
+func unwrapWhole(n Node) Node {
+	if w, ok := n.(wholeSplit); ok {
+		return w.Node
+	}
+	return n
+}
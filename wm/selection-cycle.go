@@ -4,7 +4,12 @@ func (u *Universe) CycleSelection(forwards bool) {
 	u.wmOpMutex.Lock()
 	defer u.wmOpMutex.Unlock()
 
-	u.workspaces[u.selectionIdx].contents.cycleSelection(forwards)
+	w := u.workspaces[u.selectionIdx]
+	w.floatFocused = false
+	w.contents.clearSelectedWhole()
+	w.contents.cycleSelection(forwards)
+	u.refreshRenderRect()
+	u.updateSelection()
 }
 
 func (s *split) cycleSelection(forwards bool) (bubble bool) {
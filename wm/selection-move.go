@@ -10,14 +10,20 @@ func (u *Universe) MoveSelection(d Direction) {
 }
 
 func (s *workspace) moveSelection(d Direction) {
+	s.contents.clearSelectedWhole()
+	if s.floatFocused {
+		// like in i3, moving away from a floating pane returns to the tiled ones
+		s.floatFocused = false
+		return
+	}
 	if !s.doFullscreen {
 		s.contents.moveSelection(d)
 	}
 }
 
 func (s *split) moveSelection(d Direction) (bubble bool) {
-	alignedForwards := (!s.verticallyStacked && d == Right) || (s.verticallyStacked && d == Down)
-	alignedBackward := (!s.verticallyStacked && d == Left) || (s.verticallyStacked && d == Up)
+	alignedForwards := (!s.vertical() && d == Right) || (s.vertical() && d == Down)
+	alignedBackward := (!s.vertical() && d == Left) || (s.vertical() && d == Up)
 
 	if len(s.elements) == 0 {
 		return false
@@ -5,10 +5,32 @@ func (u *Universe) updateSelection() {
 	for idx, w := range u.workspaces {
 		w.UpdateSelection(idx == u.selectionIdx)
 	}
+	u.recordFocus()
 }
 
 func (s *workspace) UpdateSelection(selected bool) {
-	s.contents.UpdateSelection(selected)
+	s.contents.UpdateSelection(selected && !s.floatFocused)
+	top := s.selectedFloat()
+	for _, f := range s.floating {
+		f.contents.UpdateSelection(selected && f == top)
+	}
+	s.drawFloatBorders()
+}
+
+// selectNode points the selection at the given node, returning false if the
+// split doesn't contain it
+func (s *split) selectNode(n Node) bool {
+	for idx, e := range s.elements {
+		found := e.contents == n
+		if child, ok := e.contents.(*split); ok && !found {
+			found = child.selectNode(n)
+		}
+		if found {
+			s.selectionIdx = idx
+			return true
+		}
+	}
+	return false
 }
 
 func (s *split) UpdateSelection(selected bool) {
@@ -16,4 +38,6 @@ func (s *split) UpdateSelection(selected bool) {
 	for idx, n := range s.elements {
 		n.contents.UpdateSelection(selected && idx == s.selectionIdx)
 	}
+	// the selected title is highlighted differently when focused
+	s.drawTitles()
 }
@@ -0,0 +1,29 @@
+package wm
+
+// Settings are options from the user's config that change how the Universe
+// arranges and draws panes
+type Settings struct {
+	// MainPaneRatio is the share of the workspace given to the main pane by
+	// the main-vertical and main-horizontal layouts
+	MainPaneRatio float32
+	// AutoBalance evens out split sizes whenever a pane is added or killed
+	AutoBalance bool
+	// PaneTitles reserves the workspace's top row as a border so that each
+	// pane's title can be drawn in the border above it
+	PaneTitles bool
+	// InnerGap is how many blank cells separate panes, in place of divider
+	// lines. OuterGap is how many surround the workspace's tiled panes.
+	InnerGap int
+	OuterGap int
+	// ScrollLines is how many lines each turn of the scroll wheel scrolls
+	ScrollLines int
+	// Theme sets the styles of borders and bars
+	Theme Theme
+}
+
+// DefaultSettings are used for anything the config leaves out
+var DefaultSettings = Settings{
+	MainPaneRatio: 0.6,
+	ScrollLines:   5,
+	Theme:         DefaultTheme,
+}
@@ -13,8 +13,10 @@ func (s *split) simplify() {
 		case *split:
 			child.simplify()
 			s.verticallyStacked = child.verticallyStacked
+			s.mode = child.mode
 			s.elements = child.elements
 			s.selectionIdx = child.selectionIdx
+			s.selectedWhole = s.selectedWhole || child.selectedWhole
 		}
 	} else {
 		newElements := []SizedNode{}
@@ -25,7 +27,10 @@ func (s *split) simplify() {
 				child.simplify()
 
 				if len(child.elements) > 0 {
-					if child.verticallyStacked == s.verticallyStacked {
+					sameKind := child.verticallyStacked == s.verticallyStacked &&
+						child.mode == modeSplit && s.mode == modeSplit &&
+						!child.selectedWhole
+					if sameKind {
 						for j := range child.elements {
 							child.elements[j].size *= n.size
 						}
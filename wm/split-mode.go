@@ -0,0 +1,224 @@
+package wm
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aaronjanse/3mux/ecma48"
+)
+
+// A splitMode decides how a split arranges its children
+type splitMode int
+
+const (
+	// modeSplit tiles children side by side or stacked, depending on
+	// verticallyStacked
+	modeSplit splitMode = iota
+	// modeTabbed shows only the selected child, below a row of tabs
+	modeTabbed
+	// modeStacking shows only the selected child, below one title row per child
+	modeStacking
+)
+
+func (u *Universe) LayoutTabbed() error {
+	return u.setSplitMode(modeTabbed)
+}
+
+func (u *Universe) LayoutStacking() error {
+	return u.setSplitMode(modeStacking)
+}
+
+// LayoutToggleSplit turns a tabbed or stacked split back into a normal split,
+// or else flips the orientation of a normal split
+func (u *Universe) LayoutToggleSplit() error {
+	return u.setSplitMode(modeSplit)
+}
+
+func (u *Universe) setSplitMode(mode splitMode) error {
+	u.wmOpMutex.Lock()
+	defer u.wmOpMutex.Unlock()
+
+	w := u.workspaces[u.selectionIdx]
+	if w.doFullscreen {
+		return errors.New("cannot change layout while a pane is fullscreen")
+	}
+
+	s := w.contents.selectedParent()
+	if mode == modeSplit && s.mode == modeSplit {
+		s.verticallyStacked = !s.verticallyStacked
+	}
+	s.mode = mode
+
+	u.refreshRenderRect()
+	u.updateSelection()
+	return nil
+}
+
+// selectedParent returns the split that directly holds the selected pane
+func (s *split) selectedParent() *split {
+	if len(s.elements) > 0 {
+		if child, ok := s.elements[s.selectionIdx].contents.(*split); ok {
+			return child.selectedParent()
+		}
+	}
+	return s
+}
+
+// vertical returns whether the split's children are arranged top to bottom,
+// which is how selection and movement treat stacked splits
+func (s *split) vertical() bool {
+	switch s.mode {
+	case modeTabbed:
+		return false
+	case modeStacking:
+		return true
+	default:
+		return s.verticallyStacked
+	}
+}
+
+// childVisible returns whether the child at the given index is on screen
+// (ignoring whether the split itself is)
+func (s *split) childVisible(idx int) bool {
+	return s.mode == modeSplit || idx == s.selectionIdx
+}
+
+// titleRows returns how many rows of titles a split draws above its children
+func (s *split) titleRows() int {
+	var rows int
+	switch s.mode {
+	case modeTabbed:
+		rows = 1
+	case modeStacking:
+		rows = len(s.elements)
+	}
+	// always leave room for the selected child
+	if rows > s.renderRect.H-1 {
+		rows = s.renderRect.H - 1
+	}
+	if rows < 0 {
+		rows = 0
+	}
+	return rows
+}
+
+// refreshTitledRect lays out a tabbed or stacked split
+func (s *split) refreshTitledRect(fullscreen bool) {
+	r := s.renderRect
+	rows := s.titleRows()
+
+	for idx, e := range s.elements {
+		e.contents.SetHidden(s.hidden || !s.childVisible(idx))
+	}
+
+	// lay out the hidden children first so that the visible one draws over
+	// anything they leave behind
+	for idx, e := range s.elements {
+		if idx != s.selectionIdx {
+			e.contents.SetRenderRect(fullscreen, r.X, r.Y+rows, r.W, r.H-rows)
+		}
+	}
+	if len(s.elements) > 0 {
+		s.elements[s.selectionIdx].contents.SetRenderRect(fullscreen, r.X, r.Y+rows, r.W, r.H-rows)
+	}
+
+	s.drawTitles()
+}
+
+// titleRect returns where the title of the child at the given index is drawn
+func (s *split) titleRect(idx int) Rect {
+	r := s.renderRect
+	if s.mode == modeTabbed {
+		x := r.X + idx*r.W/len(s.elements)
+		nextX := r.X + (idx+1)*r.W/len(s.elements)
+		return Rect{X: x, Y: r.Y, W: nextX - x, H: 1}
+	}
+	return Rect{X: r.X, Y: r.Y + idx, W: r.W, H: 1}
+}
+
+// titleAt returns the index of the child whose title is at the given
+// coordinates, or -1 if there is none
+func (s *split) titleAt(x, y int) int {
+	rows := s.titleRows()
+	for idx := range s.elements {
+		t := s.titleRect(idx)
+		if t.Y-s.renderRect.Y >= rows {
+			break
+		}
+		if t.Y == y && t.X <= x && x < t.X+t.W {
+			return idx
+		}
+	}
+	return -1
+}
+
+func (s *split) drawTitles() {
+	if s.hidden || s.mode == modeSplit {
+		return
+	}
+
+	rows := s.titleRows()
+	for idx, e := range s.elements {
+		t := s.titleRect(idx)
+		if t.Y-s.renderRect.Y >= rows {
+			break
+		}
+
+		style := s.u.settings.Theme.Tab
+		if idx == s.selectionIdx {
+			if s.selected {
+				style = s.u.settings.Theme.ActiveTab
+			} else {
+				style.Bold = true
+			}
+		}
+
+		text := []rune(" " + e.contents.Title())
+		for i := 0; i < t.W; i++ {
+			r := ' '
+			if i < len(text) {
+				r = text[i]
+			}
+			if i == t.W-1 && len(text) > t.W {
+				r = '…'
+			}
+			s.renderer.HandleCh(ecma48.PositionedChar{
+				Rune:   r,
+				Cursor: ecma48.Cursor{X: t.X + i, Y: t.Y, Style: style},
+			})
+		}
+	}
+}
+
+// redrawTitles draws the titles of every visible tabbed or stacked split
+func (s *split) redrawTitles() {
+	if s.hidden {
+		return
+	}
+	s.drawTitles()
+	for idx, e := range s.elements {
+		if child, ok := e.contents.(*split); ok && s.childVisible(idx) {
+			child.redrawTitles()
+		}
+	}
+}
+
+// Title summarizes a split like i3 does, e.g. "H[vim T[htop less]]"
+func (s *split) Title() string {
+	prefix := "H"
+	switch {
+	case s.mode == modeTabbed:
+		prefix = "T"
+	case s.mode == modeStacking:
+		prefix = "S"
+	case s.verticallyStacked:
+		prefix = "V"
+	}
+
+	titles := []string{}
+	for _, e := range s.elements {
+		titles = append(titles, e.contents.Title())
+	}
+	return fmt.Sprintf("%s[%s]", prefix, strings.Join(titles, " "))
+}
@@ -9,11 +9,15 @@ import (
 // A Split splits a region of the screen into a areas reserved for multiple child nodes
 type split struct {
 	verticallyStacked bool
+	mode              splitMode
+	hidden            bool
 	elements          []SizedNode
 	selectionIdx      int
 	renderer          ecma48.Renderer
 	renderRect        Rect
 	selected          bool
+	// selectedWhole is set when FocusParent has selected this container
+	selectedWhole bool
 
 	onDeath func(error)
 	Dead    bool
@@ -33,7 +37,7 @@ func newSplit(renderer ecma48.Renderer, u *Universe, onDeath func(error), rect R
 	}
 
 	if children == nil {
-		children = []Node{newPane(renderer)}
+		children = []Node{newPane(u.renderer, PaneSpec{})}
 	}
 
 	childSize := 1 / float32(len(children))
@@ -56,6 +60,29 @@ func (s *split) SetDeathHandler(onDeath func(error)) {
 	s.onDeath = onDeath
 }
 
+// ID returns -1 since only panes are addressable
+func (s *split) ID() int {
+	return -1
+}
+
+// Spec is empty since splits are described by layout instead
+func (s *split) Spec(history bool) PaneSpec {
+	return PaneSpec{}
+}
+
+func (s *split) SetCovered(rects []Rect) {
+	for _, n := range s.elements {
+		n.contents.SetCovered(rects)
+	}
+}
+
+func (s *split) SetHidden(hidden bool) {
+	s.hidden = hidden
+	for idx, n := range s.elements {
+		n.contents.SetHidden(hidden || !s.childVisible(idx))
+	}
+}
+
 func (s *split) Serialize() string {
 	var out string
 	if s.verticallyStacked {
@@ -64,7 +91,17 @@ func (s *split) Serialize() string {
 		out = "HSplit"
 	}
 
+	switch s.mode {
+	case modeTabbed:
+		out = "Tabbed"
+	case modeStacking:
+		out = "Stacked"
+	}
+
 	out += fmt.Sprintf("[%d]", s.selectionIdx)
+	if s.selectedWhole {
+		out += "*"
+	}
 
 	out += "("
 	for i, e := range s.elements {
@@ -95,6 +132,14 @@ func (s *split) GetRenderRect() Rect {
 // refreshRenderRect recalculates the coordinates of a Split's elements and calls setRenderRect on each of its children
 // this is for when one or more of a split's children are reshaped
 func (s *split) refreshRenderRect(fullscreen bool) {
+	if s.mode != modeSplit {
+		s.refreshTitledRect(fullscreen)
+		return
+	}
+	for _, e := range s.elements {
+		e.contents.SetHidden(s.hidden)
+	}
+
 	x := s.renderRect.X
 	y := s.renderRect.Y
 	w := s.renderRect.W
@@ -108,32 +153,44 @@ func (s *split) refreshRenderRect(fullscreen bool) {
 	} else {
 		area = w
 	}
+	gap := s.dividerWidth()
 	dividers := getDividerPositions(area, s.elements)
 	if len(s.elements) == 1 {
 		dividers = []int{area}
 	}
 	for idx, pos := range dividers {
-		lastPos := -1
+		lastPos := -gap
 		if idx > 0 {
 			lastPos = dividers[idx-1]
 		}
 
-		childArea := pos - lastPos - 1
+		childArea := pos - lastPos - gap
 		if idx == len(dividers)-1 && idx != 0 {
-			childArea = area - lastPos - 1
+			childArea = area - lastPos - gap
+		}
+		if childArea < 1 {
+			childArea = 1
 		}
 
 		childNode := s.elements[idx]
 
 		if s.verticallyStacked {
-			childNode.contents.SetRenderRect(fullscreen, x, y+lastPos+1, w, childArea)
+			childNode.contents.SetRenderRect(fullscreen, x, y+lastPos+gap, w, childArea)
 		} else {
-			childNode.contents.SetRenderRect(fullscreen, x+lastPos+1, y, childArea, h)
+			childNode.contents.SetRenderRect(fullscreen, x+lastPos+gap, y, childArea, h)
 		}
 	}
 }
 
 func (s *split) redrawLines() {
+	if s.hidden {
+		return
+	}
+	if s.mode != modeSplit {
+		s.drawTitles()
+		return
+	}
+
 	x := s.renderRect.X
 	y := s.renderRect.Y
 	w := s.renderRect.W
@@ -145,25 +202,35 @@ func (s *split) redrawLines() {
 	} else {
 		area = w
 	}
+	theme := s.u.settings.Theme
+	// gaps are left blank rather than drawn as lines
+	horizontal, vertical, style := theme.Chars.Horizontal, theme.Chars.Vertical, theme.Border
+	if s.u.settings.InnerGap > 0 {
+		horizontal, vertical, style = ' ', ' ', ecma48.Style{}
+	}
+
+	gap := s.dividerWidth()
 	dividers := getDividerPositions(area, s.elements)
 	for idx, pos := range dividers {
 		if idx == len(dividers)-1 {
 			break
 		}
 
-		if s.verticallyStacked {
-			for i := 0; i < w; i++ {
-				s.renderer.HandleCh(ecma48.PositionedChar{
-					Rune:   '─',
-					Cursor: ecma48.Cursor{X: x + i, Y: y + pos},
-				})
-			}
-		} else {
-			for j := 0; j < h; j++ {
-				s.renderer.HandleCh(ecma48.PositionedChar{
-					Rune:   '│',
-					Cursor: ecma48.Cursor{X: x + pos, Y: y + j},
-				})
+		for k := pos; k < pos+gap; k++ {
+			if s.verticallyStacked {
+				for i := 0; i < w; i++ {
+					s.renderer.HandleCh(ecma48.PositionedChar{
+						Rune:   horizontal,
+						Cursor: ecma48.Cursor{X: x + i, Y: y + k, Style: style},
+					})
+				}
+			} else {
+				for j := 0; j < h; j++ {
+					s.renderer.HandleCh(ecma48.PositionedChar{
+						Rune:   vertical,
+						Cursor: ecma48.Cursor{X: x + k, Y: y + j, Style: style},
+					})
+				}
 			}
 		}
 	}
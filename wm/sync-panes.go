@@ -0,0 +1,84 @@
+package wm
+
+import (
+	"errors"
+)
+
+// ToggleSyncPanes turns synchronized input on or off for the current
+// workspace. While it is on, whatever is typed goes to every pane in the
+// workspace, or only to those picked with ToggleSyncedPane if there are any.
+func (u *Universe) ToggleSyncPanes() {
+	u.wmOpMutex.Lock()
+	defer u.wmOpMutex.Unlock()
+
+	w := u.workspaces[u.selectionIdx]
+	w.syncPanes = !w.syncPanes
+	u.refreshRenderRect()
+}
+
+// ToggleSyncedPane adds the selected pane to the panes that synchronized
+// input goes to, or removes it
+func (u *Universe) ToggleSyncedPane() error {
+	u.wmOpMutex.Lock()
+	defer u.wmOpMutex.Unlock()
+
+	w := u.workspaces[u.selectionIdx]
+	n := w.getSelectedNode()
+	if _, ok := n.(*split); ok {
+		return errors.New("no pane is selected")
+	}
+
+	removed := false
+	for idx, x := range w.synced {
+		if x == n {
+			w.synced = append(w.synced[:idx], w.synced[idx+1:]...)
+			removed = true
+			break
+		}
+	}
+	if !removed {
+		w.synced = append(w.synced, n)
+	}
+	u.refreshRenderRect()
+	return nil
+}
+
+// syncTargets returns the panes that typed input goes to while synchronized
+// input is on. Panes that have died but not yet been removed are left out,
+// since they can no longer be written to.
+func (s *workspace) syncTargets() []Node {
+	if !s.syncPanes {
+		return nil
+	}
+	panes := []Node{}
+	for _, p := range s.panes() {
+		if !p.IsDead() {
+			panes = append(panes, p)
+		}
+	}
+	if len(s.synced) == 0 {
+		return panes
+	}
+
+	// panes picked by the user may have since died or moved away
+	targets := []Node{}
+	for _, n := range s.synced {
+		for _, p := range panes {
+			if n == p {
+				targets = append(targets, n)
+				break
+			}
+		}
+	}
+	return targets
+}
+
+// isSynced returns whether typed input is copied to a pane
+func (s *workspace) isSynced(n Node) bool {
+	for _, x := range s.syncTargets() {
+		if x == n {
+			return true
+		}
+	}
+	return false
+}
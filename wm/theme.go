@@ -0,0 +1,111 @@
+package wm
+
+import (
+	"github.com/aaronjanse/3mux/ecma48"
+)
+
+// A Theme sets how everything around the panes is drawn
+type Theme struct {
+	Border       ecma48.Style
+	ActiveBorder ecma48.Style
+	// MarkedBorder is used for the pane marked by MarkPane
+	MarkedBorder ecma48.Style
+	// SyncBorder is used for panes that typed input is copied to
+	SyncBorder ecma48.Style
+
+	// Tab and ActiveTab are used for the titles of tabbed and stacked splits
+	Tab       ecma48.Style
+	ActiveTab ecma48.Style
+
+	StatusBar ecma48.Style
+	// StatusBarActive highlights the selected workspace in the status bar
+	StatusBarActive ecma48.Style
+	HelpBar         ecma48.Style
+
+	SearchHighlight ecma48.Style
+	// SizeIndicator labels panes with their size while a border is dragged
+	SizeIndicator ecma48.Style
+
+	Chars BorderChars
+
+	// DimInactive draws every pane but the selected one faintly
+	DimInactive bool
+}
+
+// BorderChars are the characters that borders are drawn with
+type BorderChars struct {
+	Horizontal, Vertical rune
+
+	TopLeft, TopRight, BottomLeft, BottomRight rune
+
+	// TeeDown joins a divider to the border above it
+	TeeDown rune
+}
+
+// BorderCharSets are the sets of border characters that can be chosen by name
+var BorderCharSets = map[string]BorderChars{
+	"single": {
+		Horizontal: '─', Vertical: '│',
+		TopLeft: '┌', TopRight: '┐', BottomLeft: '└', BottomRight: '┘',
+		TeeDown: '┬',
+	},
+	"rounded": {
+		Horizontal: '─', Vertical: '│',
+		TopLeft: '╭', TopRight: '╮', BottomLeft: '╰', BottomRight: '╯',
+		TeeDown: '┬',
+	},
+	"heavy": {
+		Horizontal: '━', Vertical: '┃',
+		TopLeft: '┏', TopRight: '┓', BottomLeft: '┗', BottomRight: '┛',
+		TeeDown: '┳',
+	},
+	"double": {
+		Horizontal: '═', Vertical: '║',
+		TopLeft: '╔', TopRight: '╗', BottomLeft: '╚', BottomRight: '╝',
+		TeeDown: '╦',
+	},
+	"ascii": {
+		Horizontal: '-', Vertical: '|',
+		TopLeft: '+', TopRight: '+', BottomLeft: '+', BottomRight: '+',
+		TeeDown: '+',
+	},
+}
+
+// DefaultTheme is how 3mux has always looked
+var DefaultTheme = Theme{
+	ActiveBorder: ecma48.Style{
+		Fg: ecma48.Color{ColorMode: ecma48.ColorBit3Normal, Code: 6},
+	},
+	MarkedBorder: ecma48.Style{
+		Fg: ecma48.Color{ColorMode: ecma48.ColorBit3Normal, Code: 5},
+	},
+	SyncBorder: ecma48.Style{
+		Fg: ecma48.Color{ColorMode: ecma48.ColorBit3Normal, Code: 1},
+	},
+	Tab: ecma48.Style{
+		Fg: ecma48.Color{ColorMode: ecma48.ColorBit3Normal, Code: 7},
+		Bg: ecma48.Color{ColorMode: ecma48.ColorBit3Bright, Code: 0},
+	},
+	ActiveTab: ecma48.Style{
+		Fg: ecma48.Color{ColorMode: ecma48.ColorBit3Normal, Code: 0},
+		Bg: ecma48.Color{ColorMode: ecma48.ColorBit3Normal, Code: 6},
+	},
+	StatusBar: ecma48.Style{
+		Fg: ecma48.Color{ColorMode: ecma48.ColorBit3Normal, Code: 0},
+		Bg: ecma48.Color{ColorMode: ecma48.ColorBit3Normal, Code: 2},
+	},
+	StatusBarActive: ecma48.Style{
+		Bold: true,
+		Fg:   ecma48.Color{ColorMode: ecma48.ColorBit3Normal, Code: 7},
+		Bg:   ecma48.Color{ColorMode: ecma48.ColorBit3Normal, Code: 0},
+	},
+	SearchHighlight: ecma48.Style{
+		Fg: ecma48.Color{ColorMode: ecma48.ColorBit3Normal, Code: 0},
+		Bg: ecma48.Color{ColorMode: ecma48.ColorBit3Bright, Code: 2},
+	},
+	SizeIndicator: ecma48.Style{
+		Fg: ecma48.Color{ColorMode: ecma48.ColorBit3Normal, Code: 0},
+		Bg: ecma48.Color{ColorMode: ecma48.ColorBit3Normal, Code: 6},
+	},
+	Chars: BorderCharSets["single"],
+}
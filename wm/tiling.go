@@ -6,7 +6,10 @@ import (
 
 // Rect is a rectangle with an origin x, origin y, width, and height
 type Rect struct {
-	X, Y, W, H int
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
 }
 
 // A SizedNode represents a single pane of a split, having a size (relative to a total 1.0) and renderable contents
@@ -28,17 +31,29 @@ const (
 )
 
 type Node interface {
+	// ID is unique to each pane for the lifetime of the session
+	ID() int
+	// Spec describes how to recreate the pane, including its scrollback and
+	// screen if history is true
+	Spec(history bool) PaneSpec
 	SetRenderRect(fullscreen bool, x, y, w, h int)
 	GetRenderRect() Rect
 	Serialize() string
+	// Title is shown for the node in tabbed and stacked splits
+	Title() string
 	SetPaused(bool)
+	// SetHidden stops a pane from drawing while it keeps running
+	SetHidden(bool)
+	// SetCovered stops a pane from drawing within the given rects, which
+	// are taken up by floating panes
+	SetCovered([]Rect)
 	SetDeathHandler(func(error))
 	Kill()
 	IsDead() bool
 	UpdateSelection(selected bool)
 	ToggleSearch()
-	ScrollUp()
-	ScrollDown()
+	ScrollUp(lines int)
+	ScrollDown(lines int)
 	HandleStdin(ecma48.Output)
 }
 
@@ -46,8 +61,10 @@ type Container interface {
 	addPane()
 	killPane() bool
 	setFullscreen(fullscreen bool, x, y int)
-	selectAtCoords(x, y int)
-	dragBorder(x1, y1, x2, y2 int)
+	// selectAtCoords returns true if a tabbed or stacked split now shows a
+	// different child
+	selectAtCoords(x, y int) (relayout bool)
+	dividerAt(x, y int) (d divider, ok bool)
 	moveWindow(d Direction) (bubble bool, superBubble bool, p Node)
 	simplify()
 	resizePane(d Direction) (bubble bool)
@@ -57,39 +74,75 @@ type Container interface {
 	selectMax()
 	getSelectedNode() Node
 	addPaneTmux(vert bool)
+	leaves() []Node
+	detachSelected() Node
+	layout(history bool) LayoutNode
 	Node
 }
 
-type NewPaneFunc func(ecma48.Renderer) Node
+// NewPaneFunc creates a pane running what the given spec describes
+type NewPaneFunc func(ecma48.Renderer, PaneSpec) Node
 
-var FuncNames = map[string]func(*Universe){
-	"new-pane":  func(u *Universe) { u.AddPane() },
-	"kill-pane": func(u *Universe) { u.KillPane() },
+// FuncNames maps the name of each action to its implementation. These names
+// are used both in keybindings and by the session control socket.
+var FuncNames = map[string]func(*Universe) error{
+	"new-pane":  func(u *Universe) error { return u.AddPane() },
+	"kill-pane": func(u *Universe) error { u.KillPane(); return nil },
 
-	"split-pane-horiz": func(u *Universe) { u.AddPaneTmux(false) },
-	"split-pane-vert":  func(u *Universe) { u.AddPaneTmux(true) },
+	"split-pane-horiz": func(u *Universe) error { return u.AddPaneTmux(false) },
+	"split-pane-vert":  func(u *Universe) error { return u.AddPaneTmux(true) },
 
-	"show-help":     func(u *Universe) {},
-	"hide-help-bar": func(u *Universe) { u.HideHelpBar() },
+	"show-help":     func(u *Universe) error { return nil },
+	"hide-help-bar": func(u *Universe) error { u.HideHelpBar(); return nil },
 
-	"toggle-fullscreen": func(u *Universe) { u.ToggleFullscreen() },
-	"toggle-search":     func(u *Universe) { u.ToggleSearch() },
+	"layout-tabbed":       func(u *Universe) error { return u.LayoutTabbed() },
+	"layout-stacking":     func(u *Universe) error { return u.LayoutStacking() },
+	"layout-toggle-split": func(u *Universe) error { return u.LayoutToggleSplit() },
 
-	"resize-up":    func(u *Universe) { u.ResizePane(Up) },
-	"resize-down":  func(u *Universe) { u.ResizePane(Down) },
-	"resize-left":  func(u *Universe) { u.ResizePane(Left) },
-	"resize-right": func(u *Universe) { u.ResizePane(Right) },
+	"toggle-floating":       func(u *Universe) error { return u.ToggleFloating() },
+	"toggle-floating-focus": func(u *Universe) error { u.ToggleFloatingFocus(); return nil },
 
-	"move-pane-up":    func(u *Universe) { u.MoveWindow(Up) },
-	"move-pane-down":  func(u *Universe) { u.MoveWindow(Down) },
-	"move-pane-left":  func(u *Universe) { u.MoveWindow(Left) },
-	"move-pane-right": func(u *Universe) { u.MoveWindow(Right) },
+	"scratchpad-toggle":  func(u *Universe) error { return u.ToggleScratchpad() },
+	"move-to-scratchpad": func(u *Universe) error { return u.MoveToScratchpad() },
 
-	"move-selection-up":    func(u *Universe) { u.MoveSelection(Up) },
-	"move-selection-down":  func(u *Universe) { u.MoveSelection(Down) },
-	"move-selection-left":  func(u *Universe) { u.MoveSelection(Left) },
-	"move-selection-right": func(u *Universe) { u.MoveSelection(Right) },
+	"mark-pane":        func(u *Universe) error { u.MarkPane(); return nil },
+	"swap-with-marked": func(u *Universe) error { return u.SwapWithMarked() },
 
-	"cycle-selection-forward":  func(u *Universe) { u.CycleSelection(true) },
-	"cycle-selection-backward": func(u *Universe) { u.CycleSelection(false) },
+	"break-pane": func(u *Universe) error { return u.BreakPane() },
+
+	"toggle-sync-panes":  func(u *Universe) error { u.ToggleSyncPanes(); return nil },
+	"toggle-synced-pane": func(u *Universe) error { return u.ToggleSyncedPane() },
+
+	"cycle-layout": func(u *Universe) error { return u.CycleLayout() },
+
+	"balance-splits":       func(u *Universe) error { u.BalanceSplits(); return nil },
+	"balance-parent-split": func(u *Universe) error { u.BalanceParentSplit(); return nil },
+
+	"toggle-fullscreen": func(u *Universe) error { u.ToggleFullscreen(); return nil },
+	"toggle-search":     func(u *Universe) error { u.ToggleSearch(); return nil },
+
+	"resize-up":    func(u *Universe) error { u.ResizePane(Up); return nil },
+	"resize-down":  func(u *Universe) error { u.ResizePane(Down); return nil },
+	"resize-left":  func(u *Universe) error { u.ResizePane(Left); return nil },
+	"resize-right": func(u *Universe) error { u.ResizePane(Right); return nil },
+
+	"move-pane-up":    func(u *Universe) error { return u.MoveWindow(Up) },
+	"move-pane-down":  func(u *Universe) error { return u.MoveWindow(Down) },
+	"move-pane-left":  func(u *Universe) error { return u.MoveWindow(Left) },
+	"move-pane-right": func(u *Universe) error { return u.MoveWindow(Right) },
+
+	"move-selection-up":    func(u *Universe) error { u.MoveSelection(Up); return nil },
+	"move-selection-down":  func(u *Universe) error { u.MoveSelection(Down); return nil },
+	"move-selection-left":  func(u *Universe) error { u.MoveSelection(Left); return nil },
+	"move-selection-right": func(u *Universe) error { u.MoveSelection(Right); return nil },
+
+	"focus-parent": func(u *Universe) error { return u.FocusParent() },
+	"focus-child":  func(u *Universe) error { return u.FocusChild() },
+
+	"focus-last-pane": func(u *Universe) error { return u.FocusLastPane() },
+	"focus-back":      func(u *Universe) error { return u.FocusBack() },
+	"focus-forward":   func(u *Universe) error { return u.FocusForward() },
+
+	"cycle-selection-forward":  func(u *Universe) error { u.CycleSelection(true); return nil },
+	"cycle-selection-backward": func(u *Universe) error { u.CycleSelection(false); return nil },
 }
@@ -15,16 +15,37 @@ type Universe struct {
 	renderRect   Rect
 	renderer     ecma48.Renderer
 
+	// scratchpad holds hidden panes, next to be shown first
+	scratchpad []*floatingPane
+	// marked is the pane that swap-with-marked swaps with
+	marked Node
+	// history lists the panes selected so far, oldest first. historyPos is
+	// the current one, which is only before the end after FocusBack.
+	history    []Node
+	historyPos int
+
+	// drag is what the mouse is dragging, if anything
+	drag *dragState
+	// mouseGrab is the pane whose program was sent the last mouse press,
+	// until the button is released
+	mouseGrab Node
+
 	onDeath func(error)
 	dead    bool
+	newPane NewPaneFunc
 
 	helpBar         bool
 	enableStatusBar bool
+	settings        Settings
+
+	// statusRightMargin is how many columns at the right end of the status
+	// bar are left clear for labels drawn over it
+	statusRightMargin int
 
 	wmOpMutex *sync.Mutex
 }
 
-func NewUniverse(renderer ecma48.Renderer, helpBar bool, enableStatusBar bool, onDeath func(error), renderRect Rect, newPane NewPaneFunc) *Universe {
+func NewUniverse(renderer ecma48.Renderer, helpBar bool, enableStatusBar bool, settings Settings, onDeath func(error), renderRect Rect, newPane NewPaneFunc) *Universe {
 	u := &Universe{
 		selectionIdx:    0,
 		renderRect:      renderRect,
@@ -32,9 +53,11 @@ func NewUniverse(renderer ecma48.Renderer, helpBar bool, enableStatusBar bool, o
 		renderer:        renderer,
 		helpBar:         helpBar,
 		enableStatusBar: enableStatusBar,
+		settings:        settings,
 		wmOpMutex:       &sync.Mutex{},
 	}
-	u.workspaces = []*workspace{newWorkspace(renderer, u, u.handleChildDeath, renderRect, newPane)}
+	u.newPane = u.wrapNewPane(newPane)
+	u.workspaces = []*workspace{newWorkspace(1, renderer, u, u.handleChildDeath, u.workspaceRect(), u.newPane, nil)}
 	u.updateSelection()
 	u.refreshRenderRect()
 	return u
@@ -53,6 +76,9 @@ func (u *Universe) Serialize() string {
 		}
 		out += e.serialize()
 	}
+	for _, f := range u.scratchpad {
+		out += ", Scratchpad(" + f.contents.Serialize() + ")"
+	}
 	out += ")"
 
 	return out
@@ -65,6 +91,9 @@ func (u *Universe) IsDead() bool {
 // setRenderRect updates the Split's renderRect cache after which it calls refreshRenderRect
 // this for when a split is reshaped
 func (u *Universe) SetRenderRect(x, y, w, h int) {
+	u.wmOpMutex.Lock()
+	defer u.wmOpMutex.Unlock()
+
 	u.renderRect = Rect{x, y, w, h}
 
 	// NOTE: should we clear the screen?
@@ -79,37 +108,33 @@ func (u *Universe) getRenderRect() Rect {
 func (u *Universe) setPaused(pause bool) {
 	for _, n := range u.workspaces {
 		n.contents.SetPaused(pause)
+		for _, f := range n.floating {
+			f.contents.SetPaused(pause)
+		}
+	}
+	for _, f := range u.scratchpad {
+		f.contents.SetPaused(pause)
 	}
 }
 
 func (u *Universe) redrawAllLines() {
-	for _, n := range u.workspaces {
-		n.redrawAllLines()
-	}
+	u.workspaces[u.selectionIdx].redrawAllLines()
 }
 
 func (s *workspace) redrawAllLines() {
 	if !s.doFullscreen {
+		s.clearOuterGap()
 		s.contents.redrawLines()
+		s.drawTitleRow()
 	}
 }
 
 // refreshRenderRect recalculates the coordinates of a Split's elements and calls setRenderRect on each of its children
 // this is for when one or more of a split's children are reshaped
 func (u *Universe) refreshRenderRect() {
-	x := u.renderRect.X
-	y := u.renderRect.Y
-	w := u.renderRect.W
-	h := u.renderRect.H
-
+	r := u.workspaceRect()
 	for _, child := range u.workspaces {
-		if u.helpBar {
-			child.setRenderRect(x, y, w, h-2)
-		} else if u.enableStatusBar {
-			child.setRenderRect(x, y, w, h-2)
-		} else {
-			child.setRenderRect(x, y, w, h)
-		}
+		child.setRenderRect(r.X, r.Y, r.W, r.H)
 	}
 
 	if u.helpBar {
@@ -123,30 +148,67 @@ func (u *Universe) refreshRenderRect() {
 	u.drawStatusBar()
 }
 
+// workspaceRect is the area left for workspaces after the help or status bar
+func (u *Universe) workspaceRect() Rect {
+	r := u.renderRect
+	if u.helpBar || u.enableStatusBar {
+		r.H -= 2
+	}
+	return r
+}
+
 func (u *Universe) drawStatusBar() {
-	text := "3mux"
+	text := []rune("3mux ")
+
+	// the selected workspace is highlighted in the list of workspaces
+	selStart, selEnd := -1, -1
+	if len(u.workspaces) > 1 {
+		for idx, w := range u.workspaces {
+			if idx == u.selectionIdx {
+				selStart = len(text)
+			}
+			text = append(text, []rune(fmt.Sprintf(" %d ", w.num))...)
+			if idx == u.selectionIdx {
+				selEnd = len(text)
+			}
+		}
+	}
+
+	// the selected pane's title goes on the right, shortened to fit
+	var title []rune
+	if n := u.getSelectedNode(); n.ID() >= 0 {
+		title = []rune(n.Title() + " ")
+	}
+	room := u.renderRect.W - u.statusRightMargin - len(text) - 1
+	if len(title) > room {
+		if room > 2 {
+			title = append(title[:room-2:room-2], '…', ' ')
+		} else {
+			title = nil
+		}
+	}
+	titleStart := u.renderRect.W - u.statusRightMargin - len(title)
+
 	for i := 0; i < u.renderRect.W; i++ {
 		var r rune
 		if i < len(text) {
-			r = rune(text[i])
+			r = text[i]
+		} else if i >= titleStart && i-titleStart < len(title) {
+			r = title[i-titleStart]
 		} else {
 			r = 0
 		}
 
+		style := u.settings.Theme.StatusBar
+		if selStart <= i && i < selEnd {
+			style = u.settings.Theme.StatusBarActive
+		}
+
 		ch := ecma48.PositionedChar{
 			Rune: r,
 			Cursor: ecma48.Cursor{
 				X: i, Y: u.renderRect.H,
-				Style: ecma48.Style{
-					Fg: ecma48.Color{
-						ColorMode: ecma48.ColorBit3Normal,
-						Code:      0,
-					},
-					Bg: ecma48.Color{
-						ColorMode: ecma48.ColorBit3Normal,
-						Code:      2,
-					},
-				},
+				Style: style,
 			},
 		}
 
@@ -154,6 +216,18 @@ func (u *Universe) drawStatusBar() {
 	}
 }
 
+// SetStatusRightMargin leaves the given number of columns at the right end of
+// the status bar clear
+func (u *Universe) SetStatusRightMargin(n int) {
+	u.wmOpMutex.Lock()
+	defer u.wmOpMutex.Unlock()
+
+	if u.statusRightMargin != n {
+		u.statusRightMargin = n
+		u.drawStatusBar()
+	}
+}
+
 func (u *Universe) drawHelpBar() {
 	for _, hb := range helpBar {
 		if helpBarMinLen(hb[0]) > u.renderRect.W {
@@ -173,7 +247,7 @@ func (u *Universe) drawHelpBar() {
 			space += " "
 		}
 
-		style := ecma48.Style{}
+		style := u.settings.Theme.HelpBar
 
 		for line := 0; line < 2; line++ {
 			x := 0
@@ -181,9 +255,9 @@ func (u *Universe) drawHelpBar() {
 				// log.Printf("%q", r)
 				switch r {
 				case '{':
-					style.Reverse = true
+					style.Reverse = !u.settings.Theme.HelpBar.Reverse
 				case '}':
-					style.Reverse = false
+					style.Reverse = u.settings.Theme.HelpBar.Reverse
 				default:
 					u.renderer.HandleCh(ecma48.PositionedChar{
 						Rune: r,
@@ -0,0 +1,206 @@
+package wm
+
+import (
+	"errors"
+	"fmt"
+)
+
+// workspaceCount is how many workspaces can be reached with actions
+const workspaceCount = 9
+
+func init() {
+	for i := 1; i <= workspaceCount; i++ {
+		num := i
+		FuncNames[fmt.Sprintf("switch-workspace-%d", num)] = func(u *Universe) error {
+			u.SwitchWorkspace(num)
+			return nil
+		}
+		FuncNames[fmt.Sprintf("move-pane-to-workspace-%d", num)] = func(u *Universe) error {
+			return u.MovePaneToWorkspace(num)
+		}
+	}
+}
+
+// SwitchWorkspace shows the workspace with the given number, creating it if
+// it doesn't exist yet
+func (u *Universe) SwitchWorkspace(num int) {
+	u.wmOpMutex.Lock()
+	defer u.wmOpMutex.Unlock()
+
+	if u.workspaces[u.selectionIdx].num == num {
+		return
+	}
+	idx, ok := u.workspaceIdx(num)
+	if !ok {
+		idx = u.addWorkspace(num, nil)
+	}
+	u.showWorkspace(idx)
+}
+
+// MovePaneToWorkspace moves the selected pane to the workspace with the given
+// number. If it was the last pane of its workspace, that workspace is removed
+// and the pane's new workspace is shown instead.
+func (u *Universe) MovePaneToWorkspace(num int) error {
+	u.wmOpMutex.Lock()
+	defer u.wmOpMutex.Unlock()
+
+	src := u.workspaces[u.selectionIdx]
+	if src.num == num {
+		return nil
+	}
+	if src.doFullscreen {
+		return errors.New("cannot move pane while one is fullscreen")
+	}
+
+	// floating panes are tiled in their new workspace
+	p := src.detachSelected()
+	if p == nil {
+		return errors.New("no pane to move")
+	}
+	p.SetHidden(true)
+
+	idx, ok := u.workspaceIdx(num)
+	if ok {
+		dst := u.workspaces[idx]
+		dst.contents.appendNode(p)
+		dst.contents.selectionIdx = len(dst.contents.elements) - 1
+		dst.floatFocused = false
+	} else {
+		idx = u.addWorkspace(num, p)
+	}
+
+	if len(src.contents.elements) == 0 && !src.retile() {
+		u.showWorkspace(idx)
+		u.removeWorkspace(src)
+		return nil
+	}
+
+	u.simplify()
+	u.refreshRenderRect()
+	u.updateSelection()
+	return nil
+}
+
+// workspaceIdx returns the index of the workspace with the given number
+func (u *Universe) workspaceIdx(num int) (int, bool) {
+	for idx, w := range u.workspaces {
+		if w.num == num {
+			return idx, true
+		}
+	}
+	return 0, false
+}
+
+// addWorkspace creates a hidden workspace holding the given node (or a new
+// pane if it is nil) and returns its index. Workspaces are kept in order.
+func (u *Universe) addWorkspace(num int, first Node) int {
+	w := newWorkspace(num, u.renderer, u, u.handleChildDeath, u.workspaceRect(), u.newPane, first)
+	w.setHidden(true)
+
+	idx := len(u.workspaces)
+	for i, x := range u.workspaces {
+		if x.num > num {
+			idx = i
+			break
+		}
+	}
+	u.workspaces = append(u.workspaces[:idx], append([]*workspace{w}, u.workspaces[idx:]...)...)
+	if idx <= u.selectionIdx {
+		u.selectionIdx++
+	}
+	return idx
+}
+
+// removeWorkspace forgets a workspace once its last pane is gone. The
+// Universe dies along with its last workspace.
+func (u *Universe) removeWorkspace(w *workspace) {
+	idx := -1
+	for i, x := range u.workspaces {
+		if x == w {
+			idx = i
+		}
+	}
+	if idx == -1 {
+		return // already removed
+	}
+
+	u.workspaces = append(u.workspaces[:idx], u.workspaces[idx+1:]...)
+	if len(u.workspaces) == 0 && len(u.scratchpad) > 0 {
+		// panes in the scratchpad are still running, so the next one takes
+		// the place of the last workspace
+		f := u.scratchpad[0]
+		u.scratchpad = u.scratchpad[1:]
+		f.contents.SetHidden(false)
+		u.workspaces = []*workspace{newWorkspace(w.num, u.renderer, u, u.handleChildDeath, u.workspaceRect(), u.newPane, f.contents)}
+		u.selectionIdx = 0
+		u.refreshRenderRect()
+		u.updateSelection()
+		return
+	}
+	if len(u.workspaces) == 0 {
+		u.dead = true
+		u.onDeath(nil)
+		return
+	}
+
+	switch {
+	case idx < u.selectionIdx:
+		u.selectionIdx--
+		u.drawStatusBar()
+	case idx == u.selectionIdx:
+		if u.selectionIdx >= len(u.workspaces) {
+			u.selectionIdx = len(u.workspaces) - 1
+		}
+		u.workspaces[u.selectionIdx].setHidden(false)
+		u.refreshRenderRect()
+		u.updateSelection()
+	default:
+		u.drawStatusBar()
+	}
+}
+
+func (u *Universe) showWorkspace(idx int) {
+	u.workspaces[u.selectionIdx].setHidden(true)
+	u.selectionIdx = idx
+	u.workspaces[idx].setHidden(false)
+
+	u.refreshRenderRect()
+	u.updateSelection()
+}
+
+// detachSelected removes the selected pane from the tree without killing it
+func (s *split) detachSelected() Node {
+	if len(s.elements) == 0 {
+		return nil
+	}
+	switch child := s.elements[s.selectionIdx].contents.(type) {
+	case Container:
+		p := child.detachSelected()
+		if len(child.leaves()) == 0 {
+			s.popElement(s.selectionIdx)
+		}
+		return p
+	case Node:
+		s.popElement(s.selectionIdx)
+		return child
+	}
+	return nil
+}
+
+// appendNode adds a node to the end of a split, shrinking its siblings to
+// make room
+func (s *split) appendNode(n Node) {
+	size := float32(1) / float32(len(s.elements)+1)
+
+	// resize siblings
+	scaleFactor := float32(1) - size
+	for i := range s.elements {
+		s.elements[i].size *= scaleFactor
+	}
+
+	n.SetDeathHandler(s.handleChildDeath)
+	s.elements = append(s.elements, SizedNode{
+		size:     size,
+		contents: n,
+	})
+}
@@ -8,37 +8,107 @@ import (
 
 // A workspace is a desktop
 type workspace struct {
+	num          int
 	contents     *split
 	doFullscreen bool
-	renderer     ecma48.Renderer
+	renderer     *workspaceRenderer
 
+	// floating panes are drawn above contents, bottom to top
+	floating     []*floatingPane
+	floatFocused bool
+
+	// preset is the name of the layout preset applied last, if any
+	preset string
+
+	// syncPanes copies typed input to the panes in synced, or to every pane
+	// if it is empty
+	syncPanes bool
+	synced    []Node
+
+	u          *Universe
 	onDeath    func(error)
 	Dead       bool
 	newPane    NewPaneFunc
 	renderRect Rect
 }
 
-func newWorkspace(renderer ecma48.Renderer, u *Universe, onDeath func(error), renderRect Rect, newPane NewPaneFunc) *workspace {
+// newWorkspace creates a workspace holding the given node or, if it is nil, a
+// new pane
+func newWorkspace(num int, renderer ecma48.Renderer, u *Universe, onDeath func(error), renderRect Rect, newPane NewPaneFunc, first Node) *workspace {
+	return buildWorkspace(num, renderer, u, onDeath, renderRect, newPane, func(w *workspace) *split {
+		var children []Node
+		if first != nil {
+			children = []Node{first}
+		}
+		return newSplit(w.renderer, u, w.handleChildDeath, renderRect, false, 0, children, newPane)
+	})
+}
+
+// buildWorkspace is like newWorkspace, but its contents are made by build,
+// which is given the workspace to draw to and report deaths to
+func buildWorkspace(num int, renderer ecma48.Renderer, u *Universe, onDeath func(error), renderRect Rect, newPane NewPaneFunc, build func(w *workspace) *split) *workspace {
 	w := &workspace{
+		num:          num,
 		doFullscreen: false,
+		u:            u,
 		onDeath:      onDeath,
 		newPane:      newPane,
-		renderer:     renderer,
+		renderer:     &workspaceRenderer{Renderer: renderer},
 		renderRect:   renderRect,
 	}
-	w.contents = newSplit(renderer, u, w.handleChildDeath, renderRect, false, 0, nil, newPane)
+	w.contents = build(w)
 	return w
 }
 
 func (s *workspace) serialize() string {
-	return fmt.Sprintf("Workspace(%s)", s.contents.Serialize())
+	out := s.contents.Serialize()
+	for _, f := range s.floating {
+		out += ", Floating(" + f.contents.Serialize() + ")"
+	}
+	return fmt.Sprintf("Workspace(%s)", out)
 }
 
 func (s *workspace) setRenderRect(x, y, w, h int) {
 	s.renderRect = Rect{X: x, Y: y, W: w, H: h}
+	// panes must know what covers them before they redraw
+	s.refreshCovered()
 	if s.doFullscreen {
-		s.getSelectedNode().SetRenderRect(true, x, y, w, h)
+		s.selectedTarget().SetRenderRect(true, x, y, w, h)
 	} else {
-		s.contents.SetRenderRect(s.doFullscreen, x, y, w, h)
+		r := s.tiledRect()
+		s.contents.SetRenderRect(s.doFullscreen, r.X, r.Y, r.W, r.H)
+	}
+	s.refreshFloats()
+}
+
+// setHidden stops a workspace from drawing while another one is shown. Its
+// panes keep running.
+func (s *workspace) setHidden(hidden bool) {
+	s.renderer.hidden = hidden
+	s.contents.SetHidden(hidden)
+	for _, f := range s.floating {
+		f.contents.SetHidden(hidden)
+	}
+}
+
+// A workspaceRenderer passes along everything a workspace draws, except while
+// the workspace is hidden or where floating panes cover it. Panes don't draw
+// through it since they can be moved between workspaces; they are hidden and
+// covered individually instead.
+type workspaceRenderer struct {
+	ecma48.Renderer
+	hidden  bool
+	covered []Rect
+}
+
+func (r *workspaceRenderer) HandleCh(ch ecma48.PositionedChar) {
+	if !r.hidden && !covered(r.covered, ch.Cursor.X, ch.Cursor.Y) {
+		r.Renderer.HandleCh(ch)
+	}
+}
+
+func (r *workspaceRenderer) SetCursor(x, y int) {
+	if !r.hidden && !covered(r.covered, x, y) {
+		r.Renderer.SetCursor(x, y)
 	}
 }